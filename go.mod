@@ -3,4 +3,5 @@ module github.com/PabloPie/go-gandi
 require (
 	github.com/golang/mock v1.3.1
 	github.com/kolo/xmlrpc v0.0.0-20190514182600-74b23a09d7ea
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,82 @@
+package hosting
+
+import "fmt"
+
+// AccountManager represents a service capable of getting information
+// about the resources of a Gandi account
+type AccountManager interface {
+
+	// AccountInfo returns the Account the API key belongs to,
+	// with its resource quotas
+	AccountInfo() (Account, error)
+
+	// CheckQuota checks that the Account has enough resources left
+	// to create a VM from `vm` with a new Disk of `diskSize` GB and
+	// a new IP, a *QuotaError is returned if it does not
+	//
+	// A diskSize of 0 means no Disk is created
+	CheckQuota(vm VMSpec, diskSize uint) error
+}
+
+// Account is a Gandi account and the resources it can use
+type Account struct {
+	// Handle of the owner of the account
+	Handle string
+
+	// Prepaid credits left
+	Credits int
+
+	// Number of cores
+	Cores Quota
+
+	// Memory in MB
+	Memory Quota
+
+	// Disk space in GB
+	Disk Quota
+
+	// Number of IPs
+	IPs Quota
+}
+
+// Quota is the amount of a resource an Account can still
+// use and the amount it already uses
+type Quota struct {
+	Available int
+	Used      int
+}
+
+// QuotaError indicates that an Account does not have
+// enough of a resource left
+type QuotaError struct {
+	// Resource concerned: cores, memory, disk, ips
+	Resource  string
+	Needed    int
+	Available int
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("Not enough %s left: %d needed, %d available", e.Resource, e.Needed, e.Available)
+}
+
+// Fits checks that the Account has `cores` cores, `memory` MB,
+// `disk` GB and `ips` IPs available, it returns a *QuotaError
+// for the first resource that is exhausted
+func (a Account) Fits(cores, memory, disk, ips int) error {
+	needs := []struct {
+		resource string
+		needed   int
+		quota    Quota
+	}{
+		{"cores", cores, a.Cores},
+		{"memory", memory, a.Memory},
+		{"disk", disk, a.Disk},
+		{"ips", ips, a.IPs},
+	}
+	for _, need := range needs {
+		if need.needed > need.quota.Available {
+			return &QuotaError{need.resource, need.needed, need.quota.Available}
+		}
+	}
+	return nil
+}
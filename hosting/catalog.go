@@ -0,0 +1,298 @@
+package hosting
+
+import (
+	"fmt"
+	"sort"
+)
+
+// HoursPerMonth is the number of hours used to
+// turn an hourly price into a monthly one
+const HoursPerMonth = 730
+
+// Products priced in the catalog, see Price for their units
+const (
+	ProductCores     = "cores"
+	ProductMemory    = "memory"
+	ProductDisk      = "disk"
+	ProductIPv4      = "ipv4"
+	ProductIPv6      = "ipv6"
+	ProductBandwidth = "bandwidth"
+)
+
+// CatalogManager represents a service capable of getting
+// the prices of Gandi's products
+type CatalogManager interface {
+
+	// ListPrices returns the price of every product that can be
+	// created in the Region given, in `currency`, e.g. EUR, USD
+	ListPrices(region Region, currency string) (PriceList, error)
+}
+
+// Price is the hourly price of one unit of a product
+//
+// Units are: one core, one MB of memory, one GB of disk,
+// one IP and one Mbps of bandwidth
+type Price struct {
+	Product string
+	Hourly  float64
+}
+
+// PriceList contains the prices of a Region in a currency
+type PriceList struct {
+	RegionID string
+	Currency string
+
+	// Prices indexed by product
+	Prices map[string]Price
+}
+
+// CostItem is a line of an Estimate
+type CostItem struct {
+	// What is priced, e.g. "VM web1 cores"
+	Description string
+
+	// Product priced and its quantity in the product unit
+	Product  string
+	Quantity float64
+
+	Hourly  float64
+	Monthly float64
+
+	// Tags of the resource priced, only set
+	// by EstimateInventory, see Tags
+	Tags Tags
+}
+
+// Estimate is an itemized cost in a Region and a currency
+type Estimate struct {
+	RegionID string
+	Currency string
+	Items    []CostItem
+
+	// Totals of the items
+	Hourly  float64
+	Monthly float64
+}
+
+// MonthlyByTag returns the monthly cost of the items of the
+// Estimate grouped by the value of their tag `key`, the cost
+// of the items without this tag is under the empty value
+func (e Estimate) MonthlyByTag(key string) map[string]float64 {
+	costs := map[string]float64{}
+	for _, item := range e.Items {
+		costs[item.Tags[key]] += item.Monthly
+	}
+	return costs
+}
+
+// tagItems sets the tags of the items added since the `from`th one
+func (e *Estimate) tagItems(from int, tags Tags) {
+	for i := from; i < len(e.Items); i++ {
+		e.Items[i].Tags = tags
+	}
+}
+
+func (e *Estimate) add(prices PriceList, description, product string, quantity float64) error {
+	price, ok := prices.Prices[product]
+	if !ok {
+		return fmt.Errorf("No price for %s in Region '%s'", product, prices.RegionID)
+	}
+	hourly := price.Hourly * quantity
+	e.Items = append(e.Items, CostItem{
+		Description: description,
+		Product:     product,
+		Quantity:    quantity,
+		Hourly:      hourly,
+		Monthly:     hourly * HoursPerMonth,
+	})
+	e.Hourly += hourly
+	e.Monthly += hourly * HoursPerMonth
+	return nil
+}
+
+// Estimator computes the cost of resources from the prices
+// of the catalog, the prices of each Region are requested
+// once and reused for every estimate
+type Estimator struct {
+	h        Hosting
+	currency string
+	prices   map[string]PriceList
+}
+
+// NewEstimator creates an Estimator giving its costs in `currency`
+func NewEstimator(h Hosting, currency string) *Estimator {
+	return &Estimator{h, currency, map[string]PriceList{}}
+}
+
+func (e *Estimator) priceList(regionid string) (PriceList, error) {
+	if prices, ok := e.prices[regionid]; ok {
+		return prices, nil
+	}
+	prices, err := e.h.ListPrices(Region{ID: regionid}, e.currency)
+	if err != nil {
+		return PriceList{}, err
+	}
+	e.prices[regionid] = prices
+	return prices, nil
+}
+
+func (e *Estimator) newEstimate(regionid string) (*Estimate, PriceList, error) {
+	prices, err := e.priceList(regionid)
+	if err != nil {
+		return nil, PriceList{}, err
+	}
+	return &Estimate{RegionID: regionid, Currency: e.currency}, prices, nil
+}
+
+// EstimateVM returns the cost of a VM created from `vm`, with a
+// new Disk of `diskSize` GB and a new IP of version `version`
+//
+// The bandwidth of the IP is DefaultBandwidth if `vm.Bandwidth`
+// is not set
+func (e *Estimator) EstimateVM(vm VMSpec, diskSize uint, version IPVersion) (Estimate, error) {
+	estimate, prices, err := e.newEstimate(vm.RegionID)
+	if err != nil {
+		return Estimate{}, err
+	}
+
+	if err := e.addVM(estimate, prices, vm.Hostname, vm.Cores, vm.Memory); err != nil {
+		return Estimate{}, err
+	}
+	if diskSize > 0 {
+		if err := estimate.add(prices, "Disk of VM "+vm.Hostname, ProductDisk, float64(diskSize)); err != nil {
+			return Estimate{}, err
+		}
+	}
+	if err := e.addIP(estimate, prices, "IP of VM "+vm.Hostname, version); err != nil {
+		return Estimate{}, err
+	}
+
+	bandwidth := vm.Bandwidth
+	if bandwidth == 0 {
+		bandwidth = DefaultBandwidth
+	}
+	err = estimate.add(prices, "Bandwidth of VM "+vm.Hostname, ProductBandwidth, float64(bandwidth)/1024)
+	if err != nil {
+		return Estimate{}, err
+	}
+	return *estimate, nil
+}
+
+// EstimateDisk returns the cost of a Disk created from `disk`
+func (e *Estimator) EstimateDisk(disk DiskSpec) (Estimate, error) {
+	estimate, prices, err := e.newEstimate(disk.RegionID)
+	if err != nil {
+		return Estimate{}, err
+	}
+	size := disk.Size
+	if size == 0 {
+		size = 10
+	}
+	if err := estimate.add(prices, "Disk "+disk.Name, ProductDisk, float64(size)); err != nil {
+		return Estimate{}, err
+	}
+	return *estimate, nil
+}
+
+// EstimateIP returns the cost of a public IP created from `ip`,
+// its bandwidth is not included
+func (e *Estimator) EstimateIP(ip IPSpec) (Estimate, error) {
+	estimate, prices, err := e.newEstimate(ip.RegionID)
+	if err != nil {
+		return Estimate{}, err
+	}
+	if err := e.addIP(estimate, prices, "IP", ip.Version); err != nil {
+		return Estimate{}, err
+	}
+	return *estimate, nil
+}
+
+// EstimateInventory returns the cost of every VM, Disk and
+// public IP of the account, with one Estimate per Region
+//
+// Bandwidth is not included as it is not known from the IPs. Items
+// have the tags of the resource they price, see MonthlyByTag
+func (e *Estimator) EstimateInventory() ([]Estimate, error) {
+	vms, err := e.h.ListAllVMs()
+	if err != nil {
+		return nil, err
+	}
+	disks, err := e.h.ListAllDisks()
+	if err != nil {
+		return nil, err
+	}
+	ips, err := e.h.ListIPs(IPFilter{WithVlan: true})
+	if err != nil {
+		return nil, err
+	}
+
+	estimates := map[string]*Estimate{}
+	get := func(regionid string) (*Estimate, PriceList, error) {
+		prices, err := e.priceList(regionid)
+		if err != nil {
+			return nil, PriceList{}, err
+		}
+		if _, ok := estimates[regionid]; !ok {
+			estimates[regionid] = &Estimate{RegionID: regionid, Currency: e.currency}
+		}
+		return estimates[regionid], prices, nil
+	}
+
+	for _, vm := range vms {
+		estimate, prices, err := get(vm.RegionID)
+		if err != nil {
+			return nil, err
+		}
+		from := len(estimate.Items)
+		if err := e.addVM(estimate, prices, vm.Hostname, vm.Cores, vm.Memory); err != nil {
+			return nil, err
+		}
+		estimate.tagItems(from, VMTags(vm))
+	}
+	for _, disk := range disks {
+		estimate, prices, err := get(disk.RegionID)
+		if err != nil {
+			return nil, err
+		}
+		if err := estimate.add(prices, "Disk "+disk.Name, ProductDisk, float64(disk.Size)); err != nil {
+			return nil, err
+		}
+		estimate.tagItems(len(estimate.Items)-1, DiskTags(disk))
+	}
+	for _, ip := range ips {
+		// Private IPs are not charged
+		if ip.IsPrivate() {
+			continue
+		}
+		estimate, prices, err := get(ip.RegionID)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.addIP(estimate, prices, "IP "+ip.IP, ip.Version); err != nil {
+			return nil, err
+		}
+		estimate.tagItems(len(estimate.Items)-1, IPTags(ip, vms, nil))
+	}
+
+	var result []Estimate
+	for _, estimate := range estimates {
+		result = append(result, *estimate)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].RegionID < result[j].RegionID })
+	return result, nil
+}
+
+func (e *Estimator) addVM(estimate *Estimate, prices PriceList, name string, cores, memory int) error {
+	if err := estimate.add(prices, "VM "+name+" cores", ProductCores, float64(cores)); err != nil {
+		return err
+	}
+	return estimate.add(prices, "VM "+name+" memory", ProductMemory, float64(memory))
+}
+
+func (e *Estimator) addIP(estimate *Estimate, prices PriceList, description string, version IPVersion) error {
+	product := ProductIPv4
+	if version == IPv6 {
+		product = ProductIPv6
+	}
+	return estimate.add(prices, description, product, 1)
+}
@@ -0,0 +1,79 @@
+package hosting
+
+import (
+	"math"
+	"testing"
+)
+
+// inventoryHosting returns a fixed inventory and prices
+type inventoryHosting struct {
+	Hosting
+	requests int
+}
+
+func (h *inventoryHosting) ListAllVMs() ([]VM, error) {
+	return []VM{
+		{Hostname: "web1", RegionID: "1", Cores: 2, Memory: 2048, Description: "tags: team=web"},
+		{Hostname: "db1", RegionID: "2", Cores: 4, Memory: 4096},
+	}, nil
+}
+
+func (h *inventoryHosting) ListAllDisks() ([]Disk, error) {
+	return []Disk{{Name: "sys_web1", RegionID: "1", Size: 10}, {Name: "sys_db1", RegionID: "2", Size: 100}}, nil
+}
+
+func (h *inventoryHosting) ListIPs(ipfilter IPFilter) ([]IPAddress, error) {
+	return []IPAddress{
+		{ID: "10", IP: "1.2.3.4", RegionID: "1", Version: IPv4, VM: "1", State: "used"},
+		{ID: "11", IP: "192.168.0.1", RegionID: "1", Version: IPv4, VM: "1", State: "used", Vlan: "5"},
+		{ID: "12", IP: "::1", RegionID: "2", Version: IPv6, VM: "2", State: "used"},
+	}, nil
+}
+
+func (h *inventoryHosting) ListPrices(region Region, currency string) (PriceList, error) {
+	h.requests++
+	return PriceList{
+		RegionID: region.ID,
+		Currency: currency,
+		Prices: map[string]Price{
+			ProductCores:  {ProductCores, 1},
+			ProductMemory: {ProductMemory, 0.001},
+			ProductDisk:   {ProductDisk, 0.1},
+			ProductIPv4:   {ProductIPv4, 0.5},
+			ProductIPv6:   {ProductIPv6, 0},
+		},
+	}, nil
+}
+
+func TestEstimateInventory(t *testing.T) {
+	h := &inventoryHosting{}
+	estimates, err := NewEstimator(h, "EUR").EstimateInventory()
+	if err != nil {
+		t.Fatalf("Error, %s", err)
+	}
+
+	if len(estimates) != 2 || h.requests != 2 {
+		t.Fatalf("Error, expected 2 estimates from 2 requests, got instead %+v (%d)", estimates, h.requests)
+	}
+	// 2 cores, 2048 MB, 10 GB and a public IPv4
+	if estimates[0].RegionID != "1" || math.Abs(estimates[0].Hourly-5.548) > 1e-9 || len(estimates[0].Items) != 4 {
+		t.Errorf("Error, unexpected estimate %+v", estimates[0])
+	}
+	if estimates[1].Monthly != estimates[1].Hourly*HoursPerMonth {
+		t.Errorf("Error, unexpected monthly cost in %+v", estimates[1])
+	}
+
+	// The VM is tagged, its Disk and IP are not
+	byTeam := estimates[0].MonthlyByTag("team")
+	if math.Abs(byTeam["web"]-4.048*HoursPerMonth) > 1e-9 || math.Abs(byTeam[""]-1.5*HoursPerMonth) > 1e-9 {
+		t.Errorf("Error, unexpected costs by team %v", byTeam)
+	}
+}
+
+func TestEstimateMissingPrice(t *testing.T) {
+	h := &inventoryHosting{}
+	_, err := NewEstimator(h, "EUR").EstimateVM(VMSpec{RegionID: "1", Cores: 1}, 10, IPv4)
+	if err == nil {
+		t.Errorf("Error, expected error when bandwidth has no price")
+	}
+}
@@ -0,0 +1,219 @@
+package hosting
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DestroyPolicy defines how the resources depending on
+// a VM or a Vlan are handled when destroying it
+//
+// With a zero DestroyPolicy, DestroyVM behaves like DeleteVM:
+// the VM must be halted, its boot Disk and first IP are deleted
+// with it and the rest of its Disks and IPs are left detached
+type DestroyPolicy struct {
+	// Stop the VM if it is not halted, instead of failing
+	StopFirst bool
+
+	// Detach every Disk and IP of the VM before deleting it,
+	// and delete them afterwards unless they are kept
+	DetachThenDelete bool
+
+	// Detach the Disks of the VM and never delete them
+	//
+	// The boot Disk cannot be detached, it is always
+	// deleted with the VM
+	KeepDisks bool
+
+	// Detach the IPs of the VM and never delete them
+	KeepIPs bool
+
+	// Detach and delete every private IP of a Vlan before
+	// deleting it, instead of failing if the Vlan has members
+	CascadeVlanMembers bool
+}
+
+// DestroyStep is an operation done while destroying a resource
+type DestroyStep struct {
+	// Action done: stop, detach, delete
+	Action string
+
+	// Type of the resource concerned: VM, Disk, IPAddress, Vlan
+	Resource string
+
+	// ID of the resource concerned
+	ID string
+
+	// Error returned by the operation, nil on success
+	Err error
+}
+
+func (s DestroyStep) String() string {
+	if s.Err != nil {
+		return fmt.Sprintf("%s %s %s: %s", s.Action, s.Resource, s.ID, s.Err)
+	}
+	return fmt.Sprintf("%s %s %s: ok", s.Action, s.Resource, s.ID)
+}
+
+// DestroyReport lists the steps done while destroying a resource,
+// in the order they were done
+//
+// The last step is the one that failed if an error was returned
+type DestroyReport struct {
+	Steps []DestroyStep
+}
+
+func (r *DestroyReport) add(action, resource, id string, err error) error {
+	r.Steps = append(r.Steps, DestroyStep{action, resource, id, err})
+	return err
+}
+
+// DestroyVM deletes a VM and handles its Disks and IPs following `policy`
+//
+// The operations are done in order: stop, detach the Disks, detach
+// the IPs, delete the VM and delete the detached Disks and IPs. The
+// function stops at the first error, the report tells what was done
+func DestroyVM(h Hosting, vm VM, policy DestroyPolicy) (DestroyReport, error) {
+	report := DestroyReport{}
+	if vm.ID == "" {
+		return report, errors.New("VM ID not provided")
+	}
+
+	// The VM given might be outdated, we need its current
+	// state and the list of its Disks and IPs
+	vms, err := h.ListVMs(VMFilter{ID: vm.ID})
+	if err != nil {
+		return report, err
+	}
+	if len(vms) < 1 {
+		return report, fmt.Errorf("VM '%s' does not exist", vm.ID)
+	}
+	vm = vms[0]
+
+	if vm.State != "halted" {
+		if !policy.StopFirst {
+			return report, fmt.Errorf("VM '%s' is %s, it must be halted to be deleted", vm.ID, vm.State)
+		}
+		if err := report.add("stop", "VM", vm.ID, h.StopVM(vm)); err != nil {
+			return report, err
+		}
+	}
+
+	var disks []Disk
+	if policy.DetachThenDelete || policy.KeepDisks {
+		// The boot disk, at position 0, cannot be
+		// detached, it is deleted with the VM
+		for i := len(vm.Disks) - 1; i >= 1; i-- {
+			_, disk, err := h.DetachDisk(vm, vm.Disks[i])
+			if err := report.add("detach", "Disk", vm.Disks[i].ID, err); err != nil {
+				return report, err
+			}
+			disks = append(disks, disk)
+		}
+	}
+
+	var ips []IPAddress
+	if policy.DetachThenDelete || policy.KeepIPs {
+		for _, ip := range vm.Ips {
+			// Detaching an IP can detach others sharing its interface
+			attached, err := ipAttachedTo(h, ip, vm)
+			if err != nil {
+				return report, report.add("detach", "IPAddress", ip.ID, err)
+			}
+			if attached {
+				_, _, err := h.DetachIP(vm, ip)
+				if err := report.add("detach", "IPAddress", ip.ID, err); err != nil {
+					return report, err
+				}
+			}
+			ips = append(ips, ip)
+		}
+	}
+
+	if err := report.add("delete", "VM", vm.ID, h.DeleteVM(vm)); err != nil {
+		return report, err
+	}
+
+	if !policy.DetachThenDelete {
+		return report, nil
+	}
+
+	if !policy.KeepDisks {
+		for _, disk := range disks {
+			if err := report.add("delete", "Disk", disk.ID, h.DeleteDisk(disk)); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	if !policy.KeepIPs {
+		for _, ip := range ips {
+			if err := deleteIPOnce(h, ip); err != nil {
+				return report, report.add("delete", "IPAddress", ip.ID, err)
+			}
+			report.add("delete", "IPAddress", ip.ID, nil)
+		}
+	}
+
+	return report, nil
+}
+
+// DestroyVlan deletes a Vlan
+//
+// If the Vlan still has private IPs, it fails unless
+// `policy.CascadeVlanMembers` is set, in which case every
+// private IP is detached from its VM and deleted first
+func DestroyVlan(h Hosting, vlan Vlan, policy DestroyPolicy) (DestroyReport, error) {
+	report := DestroyReport{}
+	members, err := h.ListVlanMembers(vlan)
+	if err != nil {
+		return report, err
+	}
+
+	if len(members.IPs) > 0 && !policy.CascadeVlanMembers {
+		return report, fmt.Errorf("Vlan '%s' still has %d private IPs and %d VMs",
+			vlan.Name, len(members.IPs), len(members.VMs))
+	}
+
+	vms := map[string]VM{}
+	for _, vm := range members.VMs {
+		vms[vm.ID] = vm
+	}
+	for _, ip := range members.IPs {
+		if vm, ok := vms[ip.VM]; ok {
+			_, _, err := h.DetachIP(vm, ip)
+			if err := report.add("detach", "IPAddress", ip.ID, err); err != nil {
+				return report, err
+			}
+		}
+		if err := deleteIPOnce(h, ip); err != nil {
+			return report, report.add("delete", "IPAddress", ip.ID, err)
+		}
+		report.add("delete", "IPAddress", ip.ID, nil)
+	}
+
+	return report, report.add("delete", "Vlan", vlan.ID, h.DeleteVlan(vlan))
+}
+
+// ipAttachedTo tells if `ip` is currently attached to `vm`
+func ipAttachedTo(h Hosting, ip IPAddress, vm VM) (bool, error) {
+	ips, err := h.ListIPs(IPFilter{ID: ip.ID})
+	if err != nil {
+		return false, err
+	}
+	return len(ips) > 0 && ips[0].VM == vm.ID, nil
+}
+
+// deleteIPOnce deletes an IP, unless it does not exist anymore,
+// which happens when it was deleted along with another IP
+// of the same interface
+func deleteIPOnce(h Hosting, ip IPAddress) error {
+	ips, err := h.ListIPs(IPFilter{ID: ip.ID})
+	if err != nil {
+		return err
+	}
+	if len(ips) < 1 {
+		return nil
+	}
+	return h.DeleteIP(ip)
+}
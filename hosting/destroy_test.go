@@ -0,0 +1,174 @@
+package hosting
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fakeHosting records the calls made by the destroy helpers,
+// any call not implemented here panics
+type fakeHosting struct {
+	Hosting
+	vm      VM
+	ips     map[string]IPAddress
+	members VlanMembers
+	failOn  string
+	calls   []string
+}
+
+func (f *fakeHosting) call(name string) error {
+	f.calls = append(f.calls, name)
+	if name == f.failOn {
+		return errors.New("failed " + name)
+	}
+	return nil
+}
+
+func (f *fakeHosting) ListVMs(vmfilter VMFilter) ([]VM, error) {
+	return []VM{f.vm}, nil
+}
+
+func (f *fakeHosting) StopVM(vm VM) error {
+	return f.call("stop " + vm.ID)
+}
+
+func (f *fakeHosting) DeleteVM(vm VM) error {
+	return f.call("delete vm " + vm.ID)
+}
+
+func (f *fakeHosting) DetachDisk(vm VM, disk Disk) (VM, Disk, error) {
+	return vm, disk, f.call("detach disk " + disk.ID)
+}
+
+func (f *fakeHosting) DeleteDisk(disk Disk) error {
+	return f.call("delete disk " + disk.ID)
+}
+
+func (f *fakeHosting) DetachIP(vm VM, ip IPAddress) (VM, IPAddress, error) {
+	ip.VM = "0"
+	f.ips[ip.ID] = ip
+	return vm, ip, f.call("detach ip " + ip.ID)
+}
+
+func (f *fakeHosting) ListIPs(ipfilter IPFilter) ([]IPAddress, error) {
+	if ip, ok := f.ips[ipfilter.ID]; ok {
+		return []IPAddress{ip}, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeHosting) DeleteIP(ip IPAddress) error {
+	delete(f.ips, ip.ID)
+	return f.call("delete ip " + ip.ID)
+}
+
+func (f *fakeHosting) ListVlanMembers(vlan Vlan) (VlanMembers, error) {
+	return f.members, nil
+}
+
+func (f *fakeHosting) DeleteVlan(vlan Vlan) error {
+	return f.call("delete vlan " + vlan.ID)
+}
+
+func newFakeHosting(state string) *fakeHosting {
+	ips := []IPAddress{{ID: "10", VM: "1"}, {ID: "11", VM: "1"}}
+	return &fakeHosting{
+		vm: VM{
+			ID:    "1",
+			State: state,
+			Disks: []Disk{{ID: "20"}, {ID: "21"}},
+			Ips:   ips,
+		},
+		ips: map[string]IPAddress{"10": ips[0], "11": ips[1]},
+	}
+}
+
+func TestDestroyVMRunning(t *testing.T) {
+	h := newFakeHosting("running")
+
+	_, err := DestroyVM(h, VM{ID: "1"}, DestroyPolicy{})
+	if err == nil {
+		t.Errorf("Error, expected error when destroying a running VM")
+	}
+	if len(h.calls) > 0 {
+		t.Errorf("Error, expected no calls, got instead %v", h.calls)
+	}
+}
+
+func TestDestroyVMDetachThenDelete(t *testing.T) {
+	h := newFakeHosting("running")
+
+	report, err := DestroyVM(h, VM{ID: "1"}, DestroyPolicy{StopFirst: true, DetachThenDelete: true})
+	if err != nil {
+		t.Errorf("Error, %s", err)
+	}
+
+	expected := []string{
+		"stop 1",
+		"detach disk 21",
+		"detach ip 10", "detach ip 11",
+		"delete vm 1",
+		"delete disk 21",
+		"delete ip 10", "delete ip 11",
+	}
+	if !reflect.DeepEqual(expected, h.calls) {
+		t.Errorf("Error, expected %v, got instead %v", expected, h.calls)
+	}
+	if len(report.Steps) != len(expected) {
+		t.Errorf("Error, expected %d steps, got instead %v", len(expected), report.Steps)
+	}
+}
+
+func TestDestroyVMKeepDisks(t *testing.T) {
+	h := newFakeHosting("halted")
+
+	_, err := DestroyVM(h, VM{ID: "1"}, DestroyPolicy{DetachThenDelete: true, KeepDisks: true})
+	if err != nil {
+		t.Errorf("Error, %s", err)
+	}
+
+	expected := []string{
+		"detach disk 21",
+		"detach ip 10", "detach ip 11",
+		"delete vm 1",
+		"delete ip 10", "delete ip 11",
+	}
+	if !reflect.DeepEqual(expected, h.calls) {
+		t.Errorf("Error, expected %v, got instead %v", expected, h.calls)
+	}
+}
+
+func TestDestroyVMStopsAtFirstError(t *testing.T) {
+	h := newFakeHosting("halted")
+	h.failOn = "detach disk 21"
+
+	report, err := DestroyVM(h, VM{ID: "1"}, DestroyPolicy{DetachThenDelete: true})
+	if err == nil {
+		t.Errorf("Error, expected error when detaching disk 21")
+	}
+
+	last := report.Steps[len(report.Steps)-1]
+	if last.Action != "detach" || last.ID != "21" || last.Err == nil {
+		t.Errorf("Error, expected last step to be the failed detach, got instead %v", last)
+	}
+}
+
+func TestDestroyVlanWithMembers(t *testing.T) {
+	h := newFakeHosting("running")
+	h.members = VlanMembers{VMs: []VM{h.vm}, IPs: []IPAddress{h.ips["10"]}}
+
+	_, err := DestroyVlan(h, Vlan{ID: "5"}, DestroyPolicy{})
+	if err == nil {
+		t.Errorf("Error, expected error when destroying a vlan with members")
+	}
+
+	_, err = DestroyVlan(h, Vlan{ID: "5"}, DestroyPolicy{CascadeVlanMembers: true})
+	if err != nil {
+		t.Errorf("Error, %s", err)
+	}
+	expected := []string{"detach ip 10", "delete ip 10", "delete vlan 5"}
+	if !reflect.DeepEqual(expected, h.calls) {
+		t.Errorf("Error, expected %v, got instead %v", expected, h.calls)
+	}
+}
@@ -1,5 +1,13 @@
 package hosting
 
+import (
+	"errors"
+	"time"
+)
+
+// ErrDiskNotFound indicates that no Disk has the name requested
+var ErrDiskNotFound = errors.New("Disk not found")
+
 // DiskManager represents a service capable of manipulating Gandi Disks
 type DiskManager interface {
 	// CreateDisk creates a Disk object from a given DiskSpec
@@ -13,6 +21,7 @@ type DiskManager interface {
 	// A new size for the disk can be specified, but both disks
 	// must reside in the same Region, this means that the DiskSpec's
 	// `RegionID` must be the same as DiskImage's
+	// Private images from CreateImageFromDisk are also accepted
 	CreateDiskFromImage(disk DiskSpec, src DiskImage) (Disk, error)
 
 	// ListAllDisks return a list with every Disk the user has,
@@ -22,9 +31,8 @@ type DiskManager interface {
 	ListAllDisks() ([]Disk, error)
 
 	// DiskFromName returns a Disk whose name matches `name`,
-	// if no Disk is found or there was an error making the request,
-	// an empty Disk is returned
-	DiskFromName(name string) Disk
+	// if no Disk is found ErrDiskNotFound is returned
+	DiskFromName(name string) (Disk, error)
 
 	// ListDisks return a list of Disks, filtered with the options
 	// given in the DiskFilter
@@ -46,6 +54,16 @@ type DiskManager interface {
 
 	// RenameDisk renames the given Disk with the name provided
 	RenameDisk(disk Disk, name string) (Disk, error)
+
+	// ListKernels returns the names of the kernels that
+	// can be used by Disks in the Region given
+	ListKernels(region Region) ([]string, error)
+
+	// UpdateDiskKernel changes the kernel the given Disk boots
+	// with, `kernel` must be available in the Region of the Disk
+	//
+	// The new kernel is used the next time a VM boots from the Disk
+	UpdateDiskKernel(disk Disk, kernel string) (Disk, error)
 }
 
 // Disk is a Gandi disk object
@@ -77,6 +95,12 @@ type Disk struct {
 	// Set to true if the disk is attached to
 	// a VM and used as boot device
 	BootDisk bool
+
+	// Kernel the disk boots with, see ListKernels
+	Kernel string
+
+	// Date the disk was created
+	DateCreated time.Time
 }
 
 // DiskSpec contains the parameters to create a new Disk
@@ -97,10 +121,35 @@ type DiskSpec struct {
 // DiskFilter is a struct to define filtering criteria
 // for listing Disk objects
 //
-// TODO: allow a list of elements
+// A Disk must match every field set, a single value and a list
+// can both be given, e.g. `ID` and `IDs`. `Name` can be a
+// pattern, see MatchName
 type DiskFilter struct {
 	ID       string
 	RegionID string
 	Name     string
 	VMID     string
+
+	// The Disk must be one of these
+	IDs       []string
+	RegionIDs []string
+	States    []string
+
+	// Range of creation dates
+	Created DateRange
+
+	// Tags the Disks must have, see DiskTags
+	Tags Tags
+
+	// Order of the Disks returned, Fields are ignored
+	Options ListOptions
+}
+
+// DiskFromNameOrEmpty returns a Disk whose name matches `name`, or
+// an empty Disk if it does not exist or an error occurred
+//
+// Deprecated: use DiskManager.DiskFromName, which reports errors
+func DiskFromNameOrEmpty(m DiskManager, name string) Disk {
+	disk, _ := m.DiskFromName(name)
+	return disk
 }
@@ -0,0 +1,135 @@
+package hosting
+
+import "fmt"
+
+// The Ensure functions make provisioning idempotent: they look up
+// a resource by name, converge it towards the spec given if it
+// exists and only create it when it is absent
+
+// EnsureDisk returns the Disk named `disk.Name`, extended to
+// `disk.Size` if it is smaller, or creates it
+//
+// A Disk cannot shrink nor move to another Region, an error
+// is returned if the existing Disk is bigger or elsewhere
+func EnsureDisk(h Hosting, disk DiskSpec) (Disk, error) {
+	if disk.Name == "" {
+		return Disk{}, fmt.Errorf("DiskSpec must have a name to be ensured")
+	}
+	existing, err := h.DiskFromName(disk.Name)
+	if err == ErrDiskNotFound {
+		return h.CreateDisk(disk)
+	}
+	if err != nil {
+		return Disk{}, err
+	}
+
+	if disk.RegionID != "" && disk.RegionID != existing.RegionID {
+		return Disk{}, fmt.Errorf("Disk '%s' exists in Region '%s'", disk.Name, existing.RegionID)
+	}
+	if disk.Size == 0 || disk.Size == existing.Size {
+		return existing, nil
+	}
+	if disk.Size < existing.Size {
+		return Disk{}, fmt.Errorf("Disk '%s' is %d GB, it cannot shrink to %d GB",
+			disk.Name, existing.Size, disk.Size)
+	}
+	return h.ExtendDisk(existing, uint(disk.Size-existing.Size))
+}
+
+// EnsureVM returns the VM named `vm.Hostname`, with its memory and
+// cores updated if they differ from `vm`, or creates it with CreateVM
+//
+// `image`, `version` and `diskSize` are only used for the creation,
+// the Disks and IPs of an existing VM are left untouched
+func EnsureVM(h Hosting, vm VMSpec, image DiskImage, version IPVersion, diskSize uint) (VM, error) {
+	if vm.Hostname == "" {
+		return VM{}, fmt.Errorf("VMSpec must have a hostname to be ensured")
+	}
+	existing, err := h.VMFromName(vm.Hostname)
+	if err == ErrVMNotFound {
+		created, _, _, err := h.CreateVM(vm, image, version, diskSize)
+		return created, err
+	}
+	if err != nil {
+		return VM{}, err
+	}
+
+	if vm.RegionID != "" && vm.RegionID != existing.RegionID {
+		return VM{}, fmt.Errorf("VM '%s' exists in Region '%s'", vm.Hostname, existing.RegionID)
+	}
+	if vm.Memory != 0 && vm.Memory != existing.Memory {
+		if existing, err = h.UpdateVMMemory(existing, vm.Memory); err != nil {
+			return VM{}, err
+		}
+	}
+	if vm.Cores != 0 && vm.Cores != existing.Cores {
+		if existing, err = h.UpdateVMCores(existing, vm.Cores); err != nil {
+			return VM{}, err
+		}
+	}
+	return existing, nil
+}
+
+// EnsureVlan returns the Vlan named `vlan.Name`, with its gateway
+// updated if it differs from `vlan`, or creates it
+//
+// The subnet and Region of a Vlan cannot change, an error is
+// returned if the existing Vlan does not match them
+func EnsureVlan(h Hosting, vlan VlanSpec) (Vlan, error) {
+	if vlan.Name == "" {
+		return Vlan{}, fmt.Errorf("VlanSpec must have a name to be ensured")
+	}
+	existing, err := h.VlanFromName(vlan.Name)
+	if err == ErrVlanNotFound {
+		return h.CreateVlan(vlan)
+	}
+	if err != nil {
+		return Vlan{}, err
+	}
+
+	if vlan.RegionID != "" && vlan.RegionID != existing.RegionID {
+		return Vlan{}, fmt.Errorf("Vlan '%s' exists in Region '%s'", vlan.Name, existing.RegionID)
+	}
+	if vlan.Subnet != "" && vlan.Subnet != existing.Subnet {
+		return Vlan{}, fmt.Errorf("Vlan '%s' has subnet %s", vlan.Name, existing.Subnet)
+	}
+	if vlan.Gateway != "" && vlan.Gateway != existing.Gateway {
+		return h.UpdateVlanGW(existing, vlan.Gateway)
+	}
+	return existing, nil
+}
+
+// EnsureKey returns the SSHKey named `name` or, if there is none,
+// the existing SSHKey with the same fingerprint as `value` whatever
+// its name, and creates it with name `name` otherwise
+//
+// `value` is validated first, see ParsePublicKey. A key value cannot
+// be updated, an error is returned if the key named `name` differs
+func EnsureKey(m SSHKeyManager, name, value string) (SSHKey, error) {
+	key, err := ParsePublicKey(value)
+	if err != nil {
+		return SSHKey{}, err
+	}
+
+	existing, err := m.KeyFromName(name)
+	if err == nil {
+		if !sameKey(existing, key) {
+			return SSHKey{}, fmt.Errorf("SSHKey '%s' exists with another value", name)
+		}
+		return existing, nil
+	}
+	if err != ErrKeyNotFound {
+		return SSHKey{}, err
+	}
+
+	keys, err := m.ListKeys()
+	if err != nil {
+		return SSHKey{}, err
+	}
+	for _, existing := range keys {
+		if sameKey(existing, key) {
+			return existing, nil
+		}
+	}
+	return m.CreateKey(name, key.String())
+}
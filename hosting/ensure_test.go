@@ -0,0 +1,140 @@
+package hosting
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ensureHosting stores one resource of each kind in memory
+// and records the calls changing them
+type ensureHosting struct {
+	Hosting
+	disks map[string]Disk
+	vms   map[string]VM
+	vlans map[string]Vlan
+	calls []string
+}
+
+func newEnsureHosting() *ensureHosting {
+	return &ensureHosting{
+		disks: map[string]Disk{"data": {ID: "1", Name: "data", RegionID: "1", Size: 20}},
+		vms:   map[string]VM{"web1": {ID: "2", Hostname: "web1", RegionID: "1", Cores: 1, Memory: 1024}},
+		vlans: map[string]Vlan{"lan": {ID: "3", Name: "lan", RegionID: "1", Subnet: "10.0.0.0/24", Gateway: "10.0.0.1"}},
+	}
+}
+
+func (h *ensureHosting) DiskFromName(name string) (Disk, error) {
+	if disk, ok := h.disks[name]; ok {
+		return disk, nil
+	}
+	return Disk{}, ErrDiskNotFound
+}
+
+func (h *ensureHosting) CreateDisk(disk DiskSpec) (Disk, error) {
+	h.calls = append(h.calls, "create disk "+disk.Name)
+	return Disk{Name: disk.Name, RegionID: disk.RegionID, Size: disk.Size}, nil
+}
+
+func (h *ensureHosting) ExtendDisk(disk Disk, size uint) (Disk, error) {
+	h.calls = append(h.calls, "extend disk "+disk.Name)
+	disk.Size += int(size)
+	return disk, nil
+}
+
+func (h *ensureHosting) VMFromName(name string) (VM, error) {
+	if vm, ok := h.vms[name]; ok {
+		return vm, nil
+	}
+	return VM{}, ErrVMNotFound
+}
+
+func (h *ensureHosting) CreateVM(vm VMSpec, image DiskImage, version IPVersion, diskSize uint) (VM, IPAddress, Disk, error) {
+	h.calls = append(h.calls, "create vm "+vm.Hostname)
+	return VM{Hostname: vm.Hostname}, IPAddress{}, Disk{}, nil
+}
+
+func (h *ensureHosting) UpdateVMMemory(vm VM, memory int) (VM, error) {
+	h.calls = append(h.calls, "update memory "+vm.Hostname)
+	vm.Memory = memory
+	return vm, nil
+}
+
+func (h *ensureHosting) UpdateVMCores(vm VM, cores int) (VM, error) {
+	h.calls = append(h.calls, "update cores "+vm.Hostname)
+	vm.Cores = cores
+	return vm, nil
+}
+
+func (h *ensureHosting) VlanFromName(name string) (Vlan, error) {
+	if vlan, ok := h.vlans[name]; ok {
+		return vlan, nil
+	}
+	return Vlan{}, ErrVlanNotFound
+}
+
+func (h *ensureHosting) CreateVlan(vlan VlanSpec) (Vlan, error) {
+	h.calls = append(h.calls, "create vlan "+vlan.Name)
+	return Vlan{Name: vlan.Name}, nil
+}
+
+func (h *ensureHosting) UpdateVlanGW(vlan Vlan, gw string) (Vlan, error) {
+	h.calls = append(h.calls, "update gateway "+vlan.Name)
+	vlan.Gateway = gw
+	return vlan, nil
+}
+
+func TestEnsureDisk(t *testing.T) {
+	h := newEnsureHosting()
+
+	disk, err := EnsureDisk(h, DiskSpec{Name: "data", RegionID: "1", Size: 30})
+	if err != nil || disk.Size != 30 {
+		t.Errorf("Error, expected disk extended to 30 GB, got instead %+v (%v)", disk, err)
+	}
+	if _, err := EnsureDisk(h, DiskSpec{Name: "data", Size: 10}); err == nil {
+		t.Errorf("Error, expected error when shrinking a disk")
+	}
+	if _, err := EnsureDisk(h, DiskSpec{Name: "data", RegionID: "2"}); err == nil {
+		t.Errorf("Error, expected error for a disk in another region")
+	}
+	EnsureDisk(h, DiskSpec{Name: "data", Size: 20})
+	EnsureDisk(h, DiskSpec{Name: "logs", RegionID: "1"})
+
+	expected := []string{"extend disk data", "create disk logs"}
+	if !reflect.DeepEqual(expected, h.calls) {
+		t.Errorf("Error, expected %v, got instead %v", expected, h.calls)
+	}
+}
+
+func TestEnsureVM(t *testing.T) {
+	h := newEnsureHosting()
+
+	vm, err := EnsureVM(h, VMSpec{Hostname: "web1", Memory: 2048, Cores: 1}, DiskImage{}, IPv4, 10)
+	if err != nil || vm.Memory != 2048 {
+		t.Errorf("Error, expected memory updated, got instead %+v (%v)", vm, err)
+	}
+	EnsureVM(h, VMSpec{Hostname: "web2"}, DiskImage{}, IPv4, 10)
+
+	expected := []string{"update memory web1", "create vm web2"}
+	if !reflect.DeepEqual(expected, h.calls) {
+		t.Errorf("Error, expected %v, got instead %v", expected, h.calls)
+	}
+}
+
+func TestEnsureVlan(t *testing.T) {
+	h := newEnsureHosting()
+
+	vlan, err := EnsureVlan(h, VlanSpec{Name: "lan", Gateway: "10.0.0.254"})
+	if err != nil || vlan.Gateway != "10.0.0.254" {
+		t.Errorf("Error, expected gateway updated, got instead %+v (%v)", vlan, err)
+	}
+	if _, err := EnsureVlan(h, VlanSpec{Name: "lan", Subnet: "10.1.0.0/24"}); err == nil {
+		t.Errorf("Error, expected error for another subnet")
+	}
+	EnsureVlan(h, VlanSpec{Name: "lan", Gateway: "10.0.0.1"})
+	EnsureVlan(h, VlanSpec{Name: "lan2"})
+
+	expected := []string{"update gateway lan", "create vlan lan2"}
+	if !reflect.DeepEqual(expected, h.calls) {
+		t.Errorf("Error, expected %v, got instead %v", expected, h.calls)
+	}
+}
@@ -0,0 +1,119 @@
+package hosting
+
+import (
+	"strings"
+	"time"
+)
+
+// DateRange restricts resources to the ones created between
+// `After` and `Before`, a zero bound is not checked
+type DateRange struct {
+	After  time.Time
+	Before time.Time
+}
+
+// Contains reports whether `t` is in the range, bounds included
+func (r DateRange) Contains(t time.Time) bool {
+	if !r.After.IsZero() && t.Before(r.After) {
+		return false
+	}
+	if !r.Before.IsZero() && t.After(r.Before) {
+		return false
+	}
+	return true
+}
+
+// IsPattern reports whether the name given to a filter is a
+// pattern, that is if it contains a '*' wildcard, see MatchName
+func IsPattern(name string) bool {
+	return strings.Contains(name, "*")
+}
+
+// MatchName reports whether `name` matches the name given to a
+// filter: a pattern where '*' matches any sequence of characters,
+// e.g. "web*" for a prefix, or an exact name
+//
+// Case is ignored for both patterns and exact names,
+// an empty filter matches any name
+func MatchName(filter, name string) bool {
+	if filter == "" {
+		return true
+	}
+	if !IsPattern(filter) {
+		return strings.EqualFold(filter, name)
+	}
+	parts := strings.Split(strings.ToLower(filter), "*")
+	name = strings.ToLower(name)
+	if !strings.HasPrefix(name, parts[0]) {
+		return false
+	}
+	name = name[len(parts[0]):]
+	last := len(parts) - 1
+	for _, part := range parts[1:last] {
+		i := strings.Index(name, part)
+		if i < 0 {
+			return false
+		}
+		name = name[i+len(part):]
+	}
+	return strings.HasSuffix(name, parts[last])
+}
+
+// matchValue reports whether `value` is `single` if it is set
+// and is one of `list` if it is not empty
+func matchValue(value, single string, list []string) bool {
+	if single != "" && value != single {
+		return false
+	}
+	if len(list) == 0 {
+		return true
+	}
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether `vm` matches every criteria of the filter
+func (f VMFilter) Match(vm VM) bool {
+	return matchValue(vm.ID, f.ID, f.IDs) &&
+		matchValue(vm.RegionID, f.RegionID, f.RegionIDs) &&
+		matchValue(vm.State, f.State, f.States) &&
+		(f.Farm == "" || vm.Farm == f.Farm) &&
+		MatchName(f.Hostname, vm.Hostname) &&
+		f.Created.Contains(vm.DateCreated) &&
+		VMTags(vm).Match(f.Tags)
+}
+
+// Match reports whether `disk` matches every criteria of the filter
+func (f DiskFilter) Match(disk Disk) bool {
+	if f.VMID != "" && !matchValue(f.VMID, "", disk.VM) {
+		return false
+	}
+	return matchValue(disk.ID, f.ID, f.IDs) &&
+		matchValue(disk.RegionID, f.RegionID, f.RegionIDs) &&
+		matchValue(disk.State, "", f.States) &&
+		MatchName(f.Name, disk.Name) &&
+		f.Created.Contains(disk.DateCreated) &&
+		DiskTags(disk).Match(f.Tags)
+}
+
+// Match reports whether `ip` matches every criteria of the filter
+func (f IPFilter) Match(ip IPAddress) bool {
+	return matchValue(ip.ID, f.ID, f.IDs) &&
+		matchValue(ip.RegionID, f.RegionID, f.RegionIDs) &&
+		matchValue(ip.State, "", f.States) &&
+		(f.Version == 0 || ip.Version == f.Version) &&
+		(f.IP == "" || ip.IP == f.IP) &&
+		MatchName(f.Reverse, ip.Reverse) &&
+		f.Created.Contains(ip.DateCreated)
+}
+
+// Match reports whether `vlan` matches every criteria of the filter
+func (f VlanFilter) Match(vlan Vlan) bool {
+	return matchValue(vlan.ID, "", f.ID) &&
+		matchValue(vlan.RegionID, "", f.RegionID) &&
+		MatchName(f.Name, vlan.Name)
+}
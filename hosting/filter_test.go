@@ -0,0 +1,61 @@
+package hosting
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatchName(t *testing.T) {
+	tests := []struct {
+		filter, name string
+		expected     bool
+	}{
+		{"", "web1", true},
+		{"web1", "web1", true},
+		{"web1", "WEB1", true},
+		{"web", "web1", false},
+		{"web*", "Web1", true},
+		{"*-data", "web-data", true},
+		{"web*data", "web-sys", false},
+		{"w*b*1", "web1", true},
+		{"*", "", true},
+	}
+	for _, test := range tests {
+		if MatchName(test.filter, test.name) != test.expected {
+			t.Errorf("Error, expected %t for %s and %s", test.expected, test.filter, test.name)
+		}
+	}
+}
+
+func TestDateRange(t *testing.T) {
+	day := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	r := DateRange{After: day, Before: day.AddDate(0, 1, 0)}
+	if !r.Contains(day) || !r.Contains(day.AddDate(0, 0, 10)) || r.Contains(day.AddDate(0, 0, -1)) || r.Contains(day.AddDate(0, 2, 0)) {
+		t.Errorf("Error, unexpected result for %+v", r)
+	}
+	if !(DateRange{}).Contains(time.Time{}) {
+		t.Errorf("Error, an empty range must contain every date")
+	}
+}
+
+func TestVMFilterMatch(t *testing.T) {
+	vm := VM{ID: "2", RegionID: "1", Hostname: "web2", State: "running", Farm: "front",
+		DateCreated: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)}
+	filters := []struct {
+		filter   VMFilter
+		expected bool
+	}{
+		{VMFilter{}, true},
+		{VMFilter{IDs: []string{"1", "2"}, States: []string{"running", "halted"}}, true},
+		{VMFilter{ID: "2", IDs: []string{"1", "3"}}, false},
+		{VMFilter{RegionIDs: []string{"2", "3"}}, false},
+		{VMFilter{Hostname: "web*", Farm: "front"}, true},
+		{VMFilter{Created: DateRange{After: time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC)}}, false},
+		{VMFilter{Tags: Tags{FarmTag: "back"}}, false},
+	}
+	for _, test := range filters {
+		if test.filter.Match(vm) != test.expected {
+			t.Errorf("Error, expected %t for %+v", test.expected, test.filter)
+		}
+	}
+}
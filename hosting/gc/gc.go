@@ -0,0 +1,317 @@
+// Package gc finds the resources of an account that are not used
+// anymore: Disks attached to no VM, free IPs, SSH keys no VM was
+// given and Vlans without members, and deletes them on confirmation
+package gc
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/PabloPie/go-gandi/hosting"
+)
+
+// Kinds of orphaned resources
+const (
+	KindDisk   = "Disk"
+	KindIP     = "IPAddress"
+	KindSSHKey = "SSHKey"
+	KindVlan   = "Vlan"
+)
+
+// now is replaced in tests
+var now = time.Now
+
+// Filter restricts the orphaned resources reported by Find
+type Filter struct {
+	// Kinds of resources to look for, every kind if empty
+	Kinds []string
+
+	// Only report resources created at least MinAge ago
+	//
+	// SSH keys and Vlans have no creation date, they are
+	// never reported when MinAge is set
+	MinAge time.Duration
+
+	// Shell pattern the name of the resources must match, see
+	// path.Match. The address is used as the name of an IP
+	Name string
+
+	// Only report resources of a farm, those whose name starts
+	// with the name of the farm followed by '-' or '_'
+	Farm string
+
+	// Only report resources of this Region
+	RegionID string
+}
+
+// Orphan is a resource that is not used anymore
+type Orphan struct {
+	Kind     string
+	ID       string
+	Name     string
+	RegionID string
+
+	// Creation date, zero if it is not known
+	DateCreated time.Time
+
+	// Estimated cost of the resource, see Report
+	Monthly float64
+
+	// hosting.Disk, hosting.IPAddress, hosting.SSHKey or
+	// hosting.Vlan to delete
+	resource interface{}
+}
+
+func (o Orphan) String() string {
+	return fmt.Sprintf("%s %s (%s)", o.Kind, o.Name, o.ID)
+}
+
+// Report lists the orphaned resources found and what they cost
+type Report struct {
+	Orphans []Orphan
+
+	// Currency of the costs, empty if they were not estimated
+	Currency string
+
+	// Monthly cost of every Orphan
+	Monthly float64
+}
+
+// Find returns a Report of the orphaned resources matching `filter`
+//
+// If `currency` is not empty, the monthly cost of each resource is
+// estimated from the catalog, in `currency`. SSH keys, Vlans and
+// private IPs are free
+//
+// SSH keys cannot be checked when no VM reports its keys, they are
+// then skipped, or hosting.ErrKeysUnknown is returned if KindSSHKey
+// is part of the kinds of `filter`
+func Find(h hosting.Hosting, filter Filter, currency string) (Report, error) {
+	if filter.Name != "" {
+		if _, err := path.Match(filter.Name, ""); err != nil {
+			return Report{}, err
+		}
+	}
+	var orphans []Orphan
+	for _, kind := range []string{KindDisk, KindIP, KindSSHKey, KindVlan} {
+		if !filter.wants(kind) {
+			continue
+		}
+		var found []Orphan
+		var err error
+		switch kind {
+		case KindDisk:
+			found, err = findDisks(h)
+		case KindIP:
+			found, err = findIPs(h)
+		case KindSSHKey:
+			found, err = findKeys(h)
+		case KindVlan:
+			found, err = findVlans(h)
+		}
+		if err == hosting.ErrKeysUnknown && len(filter.Kinds) == 0 {
+			continue
+		}
+		if err != nil {
+			return Report{}, err
+		}
+		for _, orphan := range found {
+			if filter.match(orphan) {
+				orphans = append(orphans, orphan)
+			}
+		}
+	}
+
+	report := Report{Orphans: orphans}
+	if currency == "" {
+		return report, nil
+	}
+	report.Currency = currency
+	estimator := hosting.NewEstimator(h, currency)
+	for i, orphan := range report.Orphans {
+		var estimate hosting.Estimate
+		var err error
+		switch resource := orphan.resource.(type) {
+		case hosting.Disk:
+			estimate, err = estimator.EstimateDisk(hosting.DiskSpec{
+				RegionID: resource.RegionID,
+				Name:     resource.Name,
+				Size:     resource.Size,
+			})
+		case hosting.IPAddress:
+			if resource.IsPrivate() {
+				continue
+			}
+			estimate, err = estimator.EstimateIP(hosting.IPSpec{RegionID: resource.RegionID, Version: resource.Version})
+		default:
+			continue
+		}
+		if err != nil {
+			return Report{}, err
+		}
+		report.Orphans[i].Monthly = estimate.Monthly
+		report.Monthly += estimate.Monthly
+	}
+	return report, nil
+}
+
+// Delete deletes the Orphans of `report` for which `confirm`
+// returns true, in the order of the Report, and returns the
+// ones deleted
+//
+// It stops at the first error
+func Delete(h hosting.Hosting, report Report, confirm func(Orphan) bool) ([]Orphan, error) {
+	var deleted []Orphan
+	for _, orphan := range report.Orphans {
+		if !confirm(orphan) {
+			continue
+		}
+		var err error
+		switch resource := orphan.resource.(type) {
+		case hosting.Disk:
+			err = h.DeleteDisk(resource)
+		case hosting.IPAddress:
+			err = deleteIP(h, resource)
+		case hosting.SSHKey:
+			err = h.DeleteKey(resource)
+		case hosting.Vlan:
+			err = h.DeleteVlan(resource)
+		default:
+			err = fmt.Errorf("%s was not found by Find", orphan)
+		}
+		if err != nil {
+			return deleted, fmt.Errorf("Deleting %s: %s", orphan, err)
+		}
+		deleted = append(deleted, orphan)
+	}
+	return deleted, nil
+}
+
+// deleteIP deletes an IP unless it does not exist anymore: the
+// IPv4 and the IPv6 of a free interface are both reported, and
+// deleting one of them deletes the interface with the other one
+func deleteIP(h hosting.Hosting, ip hosting.IPAddress) error {
+	ips, err := h.ListIPs(hosting.IPFilter{ID: ip.ID})
+	if err != nil {
+		return err
+	}
+	if len(ips) < 1 {
+		return nil
+	}
+	return h.DeleteIP(ip)
+}
+
+func (f Filter) wants(kind string) bool {
+	if len(f.Kinds) == 0 {
+		return true
+	}
+	for _, k := range f.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) match(orphan Orphan) bool {
+	if f.RegionID != "" && orphan.RegionID != f.RegionID {
+		return false
+	}
+	if f.MinAge > 0 && (orphan.DateCreated.IsZero() || now().Sub(orphan.DateCreated) < f.MinAge) {
+		return false
+	}
+	if f.Name != "" {
+		if ok, _ := path.Match(f.Name, orphan.Name); !ok {
+			return false
+		}
+	}
+	if f.Farm != "" && !strings.HasPrefix(orphan.Name, f.Farm+"-") && !strings.HasPrefix(orphan.Name, f.Farm+"_") {
+		return false
+	}
+	return true
+}
+
+// findDisks returns the Disks attached to no VM, private
+// images and Disks being created or deleted are not orphans
+func findDisks(h hosting.Hosting) ([]Orphan, error) {
+	disks, err := h.ListAllDisks()
+	if err != nil {
+		return nil, err
+	}
+	var orphans []Orphan
+	for _, disk := range disks {
+		if len(disk.VM) > 0 || disk.Type == "image" || disk.State != "created" {
+			continue
+		}
+		orphans = append(orphans, Orphan{KindDisk, disk.ID, disk.Name, disk.RegionID, disk.DateCreated, 0, disk})
+	}
+	return orphans, nil
+}
+
+// findIPs returns the IPs whose state is free
+func findIPs(h hosting.Hosting) ([]Orphan, error) {
+	ips, err := h.ListIPs(hosting.IPFilter{WithVlan: true})
+	if err != nil {
+		return nil, err
+	}
+	var orphans []Orphan
+	for _, ip := range ips {
+		if ip.State != "free" {
+			continue
+		}
+		orphans = append(orphans, Orphan{KindIP, ip.ID, ip.IP, ip.RegionID, ip.DateCreated, 0, ip})
+	}
+	return orphans, nil
+}
+
+// findKeys returns the SSHKeys that no VM was given, the keys
+// of the VMs are not known if none of them reports a key
+func findKeys(h hosting.Hosting) ([]Orphan, error) {
+	keys, err := h.ListKeys()
+	if err != nil {
+		return nil, err
+	}
+	vms, err := h.ListAllVMs()
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]bool{}
+	for _, vm := range vms {
+		for _, key := range vm.SSHKeys {
+			used[key] = true
+		}
+	}
+	if len(used) == 0 && len(keys) > 0 {
+		return nil, hosting.ErrKeysUnknown
+	}
+	var orphans []Orphan
+	for _, key := range keys {
+		if used[key.Name] {
+			continue
+		}
+		orphans = append(orphans, Orphan{Kind: KindSSHKey, ID: key.ID, Name: key.Name, resource: key})
+	}
+	return orphans, nil
+}
+
+// findVlans returns the Vlans without VMs nor private IPs
+func findVlans(h hosting.Hosting) ([]Orphan, error) {
+	vlans, err := h.ListVlans(hosting.VlanFilter{})
+	if err != nil {
+		return nil, err
+	}
+	var orphans []Orphan
+	for _, vlan := range vlans {
+		members, err := h.ListVlanMembers(vlan)
+		if err != nil {
+			return nil, err
+		}
+		if len(members.VMs) > 0 || len(members.IPs) > 0 {
+			continue
+		}
+		orphans = append(orphans, Orphan{Kind: KindVlan, ID: vlan.ID, Name: vlan.Name, RegionID: vlan.RegionID, resource: vlan})
+	}
+	return orphans, nil
+}
@@ -0,0 +1,240 @@
+package gc
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/PabloPie/go-gandi/hosting"
+)
+
+var created = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// gcHosting has one used and one orphaned
+// resource of each kind, in Region 1
+type gcHosting struct {
+	hosting.Hosting
+	deleted []string
+}
+
+func (h *gcHosting) ListAllDisks() ([]hosting.Disk, error) {
+	return []hosting.Disk{
+		{ID: "1", Name: "web-sys", RegionID: "1", Size: 10, State: "created", Type: "data", VM: []string{"10"}},
+		{ID: "2", Name: "web-data", RegionID: "1", Size: 100, State: "created", Type: "data", DateCreated: created},
+		{ID: "3", Name: "debian-custom", RegionID: "1", Size: 3, State: "created", Type: "image"},
+	}, nil
+}
+
+func (h *gcHosting) ListIPs(ipfilter hosting.IPFilter) ([]hosting.IPAddress, error) {
+	return []hosting.IPAddress{
+		{ID: "4", IP: "1.2.3.4", RegionID: "1", Version: hosting.IPv4, State: "used", VM: "10"},
+		{ID: "5", IP: "1.2.3.5", RegionID: "1", Version: hosting.IPv4, State: "free", DateCreated: created.AddDate(0, 6, 0)},
+	}, nil
+}
+
+func (h *gcHosting) ListKeys() ([]hosting.SSHKey, error) {
+	return []hosting.SSHKey{{ID: "6", Name: "admin"}, {ID: "7", Name: "web-deploy"}}, nil
+}
+
+func (h *gcHosting) ListAllVMs() ([]hosting.VM, error) {
+	return []hosting.VM{{ID: "10", Hostname: "web1", SSHKeys: []string{"admin"}}}, nil
+}
+
+func (h *gcHosting) ListVlans(vlanfilter hosting.VlanFilter) ([]hosting.Vlan, error) {
+	return []hosting.Vlan{{ID: "8", Name: "web_lan", RegionID: "1"}, {ID: "9", Name: "db_lan", RegionID: "1"}}, nil
+}
+
+func (h *gcHosting) ListVlanMembers(vlan hosting.Vlan) (hosting.VlanMembers, error) {
+	if vlan.ID == "9" {
+		return hosting.VlanMembers{IPs: []hosting.IPAddress{{ID: "11"}}}, nil
+	}
+	return hosting.VlanMembers{}, nil
+}
+
+func (h *gcHosting) ListPrices(region hosting.Region, currency string) (hosting.PriceList, error) {
+	return hosting.PriceList{RegionID: region.ID, Currency: currency, Prices: map[string]hosting.Price{
+		hosting.ProductDisk: {Product: hosting.ProductDisk, Hourly: 0.001},
+		hosting.ProductIPv4: {Product: hosting.ProductIPv4, Hourly: 0.01},
+		hosting.ProductIPv6: {Product: hosting.ProductIPv6, Hourly: 0},
+	}}, nil
+}
+
+func (h *gcHosting) DeleteDisk(disk hosting.Disk) error {
+	h.deleted = append(h.deleted, "disk "+disk.ID)
+	return nil
+}
+
+func (h *gcHosting) DeleteIP(ip hosting.IPAddress) error {
+	return errors.New("locked")
+}
+
+func (h *gcHosting) DeleteKey(key hosting.SSHKey) error {
+	h.deleted = append(h.deleted, "key "+key.ID)
+	return nil
+}
+
+// ifaceHosting has a free public interface with an IPv4 and an
+// IPv6, and a free private IP, deleting an IP deletes the other
+// IPs of its interface
+type ifaceHosting struct {
+	gcHosting
+	ips []hosting.IPAddress
+}
+
+func (h *ifaceHosting) ListIPs(ipfilter hosting.IPFilter) ([]hosting.IPAddress, error) {
+	var ips []hosting.IPAddress
+	for _, ip := range h.ips {
+		if ipfilter.Match(ip) {
+			ips = append(ips, ip)
+		}
+	}
+	return ips, nil
+}
+
+func (h *ifaceHosting) DeleteIP(ip hosting.IPAddress) error {
+	h.deleted = append(h.deleted, "ip "+ip.ID)
+	var ips []hosting.IPAddress
+	for _, other := range h.ips {
+		// The public IPs share an interface
+		if other.ID != ip.ID && (ip.Vlan != "" || other.Vlan != "") {
+			ips = append(ips, other)
+		}
+	}
+	h.ips = ips
+	return nil
+}
+
+func newIfaceHosting() *ifaceHosting {
+	return &ifaceHosting{ips: []hosting.IPAddress{
+		{ID: "4", IP: "1.2.3.4", RegionID: "1", Version: hosting.IPv4, State: "free", VM: "0"},
+		{ID: "5", IP: "2001:db8::1", RegionID: "1", Version: hosting.IPv6, State: "free", VM: "0"},
+		{ID: "6", IP: "192.168.0.1", RegionID: "1", Version: hosting.IPv4, State: "free", VM: "0", Vlan: "8"},
+	}}
+}
+
+func names(orphans []Orphan) []string {
+	var result []string
+	for _, orphan := range orphans {
+		result = append(result, orphan.Name)
+	}
+	return result
+}
+
+func TestFind(t *testing.T) {
+	h := &gcHosting{}
+	report, err := Find(h, Filter{}, "EUR")
+	if err != nil {
+		t.Fatalf("Error, %s", err)
+	}
+	expected := []string{"web-data", "1.2.3.5", "web-deploy", "web_lan"}
+	if !reflect.DeepEqual(expected, names(report.Orphans)) {
+		t.Errorf("Error, expected %v, got instead %v", expected, names(report.Orphans))
+	}
+	// 100 GB and an IPv4
+	monthly := (100*0.001 + 0.01) * hosting.HoursPerMonth
+	if math.Abs(report.Monthly-monthly) > 1e-9 || report.Orphans[2].Monthly != 0 {
+		t.Errorf("Error, expected %f per month, got instead %+v", monthly, report)
+	}
+}
+
+func TestFindFilter(t *testing.T) {
+	now = func() time.Time { return created.AddDate(1, 0, 0) }
+	defer func() { now = time.Now }()
+
+	h := &gcHosting{}
+	filters := []struct {
+		filter   Filter
+		expected []string
+	}{
+		{Filter{Kinds: []string{KindDisk, KindVlan}}, []string{"web-data", "web_lan"}},
+		{Filter{MinAge: 9 * 30 * 24 * time.Hour}, []string{"web-data"}},
+		{Filter{Name: "1.2.*"}, []string{"1.2.3.5"}},
+		{Filter{Farm: "web"}, []string{"web-data", "web-deploy", "web_lan"}},
+		{Filter{RegionID: "2"}, nil},
+	}
+	for _, test := range filters {
+		report, err := Find(h, test.filter, "")
+		if err != nil {
+			t.Fatalf("Error, %s", err)
+		}
+		if !reflect.DeepEqual(test.expected, names(report.Orphans)) {
+			t.Errorf("Error, expected %v for %+v, got instead %v", test.expected, test.filter, names(report.Orphans))
+		}
+	}
+
+	if _, err := Find(h, Filter{Name: "["}, ""); err == nil {
+		t.Errorf("Error, expected error for a bad pattern")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	h := &gcHosting{}
+	report, _ := Find(h, Filter{}, "")
+
+	deleted, err := Delete(h, report, func(o Orphan) bool { return o.Kind != KindVlan })
+	if err == nil {
+		t.Errorf("Error, expected error deleting the IP")
+	}
+	if !reflect.DeepEqual([]string{"web-data"}, names(deleted)) || !reflect.DeepEqual([]string{"disk 2"}, h.deleted) {
+		t.Errorf("Error, unexpected deletions %v, %v", names(deleted), h.deleted)
+	}
+
+	h.deleted = nil
+	deleted, err = Delete(h, report, func(o Orphan) bool { return o.Kind == KindSSHKey })
+	if err != nil || len(deleted) != 1 || !reflect.DeepEqual([]string{"key 7"}, h.deleted) {
+		t.Errorf("Error, unexpected deletions %v, %v (%v)", names(deleted), h.deleted, err)
+	}
+}
+
+func TestFindPrivateIP(t *testing.T) {
+	h := newIfaceHosting()
+	report, err := Find(h, Filter{Kinds: []string{KindIP}}, "EUR")
+	if err != nil {
+		t.Fatalf("Error, %s", err)
+	}
+	if len(report.Orphans) != 3 || report.Orphans[2].Monthly != 0 {
+		t.Errorf("Error, expected the private IP to be free, got instead %+v", report)
+	}
+}
+
+func TestDeleteIPsOfSameInterface(t *testing.T) {
+	h := newIfaceHosting()
+	report, _ := Find(h, Filter{Kinds: []string{KindIP}}, "")
+
+	deleted, err := Delete(h, report, func(o Orphan) bool { return true })
+	if err != nil {
+		t.Errorf("Error, %s", err)
+	}
+	if len(deleted) != 3 || !reflect.DeepEqual([]string{"ip 4", "ip 6"}, h.deleted) {
+		t.Errorf("Error, unexpected deletions %v, %v", names(deleted), h.deleted)
+	}
+}
+
+// unknownKeysHosting has VMs that do not report their keys
+type unknownKeysHosting struct {
+	gcHosting
+}
+
+func (h *unknownKeysHosting) ListAllVMs() ([]hosting.VM, error) {
+	return []hosting.VM{{ID: "10", Hostname: "web1"}}, nil
+}
+
+func TestFindUnknownKeys(t *testing.T) {
+	h := &unknownKeysHosting{}
+	report, err := Find(h, Filter{}, "")
+	if err != nil {
+		t.Fatalf("Error, %s", err)
+	}
+	for _, orphan := range report.Orphans {
+		if orphan.Kind == KindSSHKey {
+			t.Errorf("Error, expected no SSHKey to be reported, got %s", orphan)
+		}
+	}
+
+	_, err = Find(h, Filter{Kinds: []string{KindSSHKey}}, "")
+	if err != hosting.ErrKeysUnknown {
+		t.Errorf("Error, expected %v, got instead %v", hosting.ErrKeysUnknown, err)
+	}
+}
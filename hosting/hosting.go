@@ -1,5 +1,11 @@
 // Package hosting contains the interfaces and data structures that a user
-// will use to interact with the lib
+// will use to interact with the lib, and the helpers built on top of them
+// that do not depend on a specific version of the API
+//
+// The filters given to the List functions, e.g. VMFilter, have a Match
+// method that the implementations apply to the resources returned by
+// the API, for the criteria the API cannot filter on itself, such as
+// tags or creation dates
 package hosting
 
 // Hosting represents Gandi's API and contains every functionality
@@ -56,4 +62,17 @@ type Hosting interface {
 	// - Listing images in a Region
 	// - Searching images by name
 	ImageManager
+
+	// AccountManager is an interface containing the operations to
+	// obtain information about the account used
+	//
+	// - Resource quotas
+	// - Pre-flight checks before creating resources
+	AccountManager
+
+	// CatalogManager is an interface containing the operations to
+	// obtain the prices of Gandi's products
+	//
+	// - Price listing per Region and currency
+	CatalogManager
 }
@@ -0,0 +1,81 @@
+package hostingv4
+
+import (
+	"github.com/PabloPie/go-gandi/hosting"
+)
+
+type accountv4 struct {
+	Handle    string      `xmlrpc:"handle"`
+	Credits   int         `xmlrpc:"credits"`
+	Resources resourcesv4 `xmlrpc:"resources"`
+}
+
+type resourcesv4 struct {
+	Available resourceQuotav4 `xmlrpc:"available"`
+	Used      resourceQuotav4 `xmlrpc:"used"`
+}
+
+type resourceQuotav4 struct {
+	Cores  int `xmlrpc:"cores"`
+	Memory int `xmlrpc:"memory"` // in MB
+	Disk   int `xmlrpc:"disk"`   // in MB
+	IPs    int `xmlrpc:"ips"`
+}
+
+// AccountInfo returns the account the API key belongs to
+func (h Hostingv4) AccountInfo() (hosting.Account, error) {
+	response := accountv4{}
+	params := []interface{}{}
+	err := h.Send("hosting.account.info", params, &response)
+	if err != nil {
+		return hosting.Account{}, err
+	}
+	return fromAccountv4(response), nil
+}
+
+// CheckQuota checks that the account can create a hosting.VM from `vm`,
+// with a new disk of `diskSize` GB and a new IP
+func (h Hostingv4) CheckQuota(vm hosting.VMSpec, diskSize uint) error {
+	return h.checkQuota(vm, int(diskSize), 1)
+}
+
+// Cores and memory, in MB, given by the API to a VM created
+// without them
+const (
+	defaultCoresv4  = 1
+	defaultMemoryv4 = 256
+)
+
+// checkQuota checks the quotas for the cores and memory of `vm`,
+// `disk` GB of disk space and `ips` IPs
+//
+// The cores and memory not set in `vm` are checked with the
+// defaults of the API
+func (h Hostingv4) checkQuota(vm hosting.VMSpec, disk int, ips int) error {
+	account, err := h.AccountInfo()
+	if err != nil {
+		return err
+	}
+	cores, memory := vm.Cores, vm.Memory
+	if cores == 0 {
+		cores = defaultCoresv4
+	}
+	if memory == 0 {
+		memory = defaultMemoryv4
+	}
+	return account.Fits(cores, memory, disk, ips)
+}
+
+// accountv4 -> Hosting hosting.Account
+func fromAccountv4(account accountv4) hosting.Account {
+	available := account.Resources.Available
+	used := account.Resources.Used
+	return hosting.Account{
+		Handle:  account.Handle,
+		Credits: account.Credits,
+		Cores:   hosting.Quota{Available: available.Cores, Used: used.Cores},
+		Memory:  hosting.Quota{Available: available.Memory, Used: used.Memory},
+		Disk:    hosting.Quota{Available: available.Disk / 1024, Used: used.Disk / 1024},
+		IPs:     hosting.Quota{Available: available.IPs, Used: used.IPs},
+	}
+}
@@ -0,0 +1,110 @@
+package hostingv4
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/PabloPie/go-gandi/hosting"
+	"github.com/PabloPie/go-gandi/mock"
+	"github.com/golang/mock/gomock"
+)
+
+var account = accountv4{
+	Handle:  "AB1234-GANDI",
+	Credits: 5000,
+	Resources: resourcesv4{
+		Available: resourceQuotav4{Cores: 2, Memory: 2048, Disk: 51200, IPs: 1},
+		Used:      resourceQuotav4{Cores: 6, Memory: 6144, Disk: 102400, IPs: 3},
+	},
+}
+
+func TestAccountInfo(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	mockClient.EXPECT().Send("hosting.account.info",
+		[]interface{}{}, gomock.Any()).SetArg(2, account).Return(nil)
+
+	info, _ := testHosting.AccountInfo()
+	expected := hosting.Account{
+		Handle:  "AB1234-GANDI",
+		Credits: 5000,
+		Cores:   hosting.Quota{Available: 2, Used: 6},
+		Memory:  hosting.Quota{Available: 2048, Used: 6144},
+		Disk:    hosting.Quota{Available: 50, Used: 100},
+		IPs:     hosting.Quota{Available: 1, Used: 3},
+	}
+
+	if !reflect.DeepEqual(expected, info) {
+		t.Errorf("Error, expected %+v, got instead %+v", expected, info)
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	tests := []struct {
+		vm       hosting.VMSpec
+		diskSize uint
+		expected error
+	}{
+		{hosting.VMSpec{Cores: 2, Memory: 2048}, 50, nil},
+		{hosting.VMSpec{Cores: 4, Memory: 1024}, 10, &hosting.QuotaError{Resource: "cores", Needed: 4, Available: 2}},
+		{hosting.VMSpec{Cores: 1, Memory: 4096}, 10, &hosting.QuotaError{Resource: "memory", Needed: 4096, Available: 2048}},
+		{hosting.VMSpec{Cores: 1, Memory: 1024}, 60, &hosting.QuotaError{Resource: "disk", Needed: 60, Available: 50}},
+	}
+
+	for _, test := range tests {
+		mockCtrl := gomock.NewController(t)
+		mockClient := mock.NewMockV4Caller(mockCtrl)
+		testHosting := Newv4Hosting(mockClient)
+
+		mockClient.EXPECT().Send("hosting.account.info",
+			[]interface{}{}, gomock.Any()).SetArg(2, account).Return(nil)
+
+		err := testHosting.CheckQuota(test.vm, test.diskSize)
+		if !reflect.DeepEqual(test.expected, err) {
+			t.Errorf("Error, expected %+v, got instead %+v", test.expected, err)
+		}
+		mockCtrl.Finish()
+	}
+}
+
+func TestCheckQuotaDefaults(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	nocores := accountv4{Resources: resourcesv4{
+		Available: resourceQuotav4{Cores: 0, Memory: 2048, Disk: 51200, IPs: 1},
+	}}
+	mockClient.EXPECT().Send("hosting.account.info",
+		[]interface{}{}, gomock.Any()).SetArg(2, nocores).Return(nil)
+
+	// The VM gets the cores of the API by default
+	err := testHosting.CheckQuota(hosting.VMSpec{}, 10)
+	expected := &hosting.QuotaError{Resource: "cores", Needed: defaultCoresv4, Available: 0}
+	if !reflect.DeepEqual(expected, err) {
+		t.Errorf("Error, expected %+v, got instead %+v", expected, err)
+	}
+}
+
+func TestCreateVMQuotaExceeded(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	// Nothing but the account info is requested
+	mockClient.EXPECT().Send("hosting.account.info",
+		[]interface{}{}, gomock.Any()).SetArg(2, account).Return(nil)
+
+	vmspec := hosting.VMSpec{RegionID: regionstr, Hostname: vmname, Cores: 4, CheckQuota: true}
+	image := hosting.DiskImage{DiskID: imageidstr, RegionID: regionstr}
+	_, _, _, err := testHosting.CreateVM(vmspec, image, hosting.IPv4, 20)
+
+	if _, ok := err.(*hosting.QuotaError); !ok {
+		t.Errorf("Error, expected a QuotaError, got instead %+v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package hostingv4
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/PabloPie/go-gandi/hosting"
+)
+
+type catalogItemv4 struct {
+	Product   productv4     `xmlrpc:"product"`
+	UnitPrice []unitPricev4 `xmlrpc:"unit_price"`
+}
+
+type productv4 struct {
+	Type        string `xmlrpc:"type"`
+	Description string `xmlrpc:"description"`
+}
+
+type unitPricev4 struct {
+	Price    float64 `xmlrpc:"price"`
+	Duration string  `xmlrpc:"duration"`
+	Grid     string  `xmlrpc:"grid"`
+}
+
+// v4 product types and their hosting equivalent, IPs
+// are told apart by their description
+var productsv4 = map[string]string{
+	"cores":     hosting.ProductCores,
+	"ram":       hosting.ProductMemory,
+	"disk_data": hosting.ProductDisk,
+	"bandwidth": hosting.ProductBandwidth,
+	"ipv4":      hosting.ProductIPv4,
+	"ipv6":      hosting.ProductIPv6,
+}
+
+// ListPrices returns the prices of the products in `region`, in `currency`
+//
+// Products without a known hosting equivalent are ignored
+func (h Hostingv4) ListPrices(region hosting.Region, currency string) (hosting.PriceList, error) {
+	var fn = "ListPrices"
+	if region.ID == "" {
+		return hosting.PriceList{}, &HostingError{fn, "Region", "ID", ErrNotProvided}
+	}
+	regionid, err := strconv.Atoi(region.ID)
+	if err != nil {
+		return hosting.PriceList{}, &HostingError{fn, "Region", "ID", ErrParse}
+	}
+	if currency == "" {
+		return hosting.PriceList{}, &HostingError{fn, "-", "currency", ErrNotProvided}
+	}
+
+	response := []catalogItemv4{}
+	filter := map[string]int{"datacenter_id": regionid}
+	params := []interface{}{filter, strings.ToUpper(currency)}
+	err = h.Send("catalog.list", params, &response)
+	if err != nil {
+		return hosting.PriceList{}, err
+	}
+
+	prices := hosting.PriceList{
+		RegionID: region.ID,
+		Currency: strings.ToUpper(currency),
+		Prices:   map[string]hosting.Price{},
+	}
+	for _, item := range response {
+		price, ok := fromCatalogItemv4(item)
+		if ok {
+			prices.Prices[price.Product] = price
+		}
+	}
+	return prices, nil
+}
+
+// catalogItemv4 -> Hosting hosting.Price
+//
+// The first unit price, the one of the default grid, is used
+// and monthly prices are turned into hourly ones
+func fromCatalogItemv4(item catalogItemv4) (hosting.Price, bool) {
+	name := item.Product.Type
+	if name == "ip" {
+		name = item.Product.Description
+	}
+	product, ok := productsv4[name]
+	if !ok || len(item.UnitPrice) < 1 {
+		return hosting.Price{}, false
+	}
+
+	unitprice := item.UnitPrice[0]
+	hourly := unitprice.Price
+	if unitprice.Duration == "1m" {
+		hourly /= hosting.HoursPerMonth
+	}
+	return hosting.Price{Product: product, Hourly: hourly}, true
+}
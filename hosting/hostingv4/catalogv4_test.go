@@ -0,0 +1,81 @@
+package hostingv4
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/PabloPie/go-gandi/hosting"
+	"github.com/PabloPie/go-gandi/mock"
+	"github.com/golang/mock/gomock"
+)
+
+var catalog = []catalogItemv4{
+	{productv4{"cores", "cores"}, []unitPricev4{{0.01, "1h", "A"}}},
+	{productv4{"ram", "ram"}, []unitPricev4{{0.00001, "1h", "A"}}},
+	{productv4{"disk_data", "disk"}, []unitPricev4{{0.073, "1m", "A"}}},
+	{productv4{"ip", "ipv4"}, []unitPricev4{{0.002, "1h", "A"}}},
+	{productv4{"ip", "ipv6"}, []unitPricev4{{0, "1h", "A"}}},
+	{productv4{"bandwidth", "bandwidth"}, []unitPricev4{{0, "1h", "A"}}},
+	{productv4{"simple_hosting", "paas"}, []unitPricev4{{1, "1h", "A"}}},
+}
+
+func TestListPrices(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	mockClient.EXPECT().Send("catalog.list",
+		[]interface{}{map[string]int{"datacenter_id": region}, "EUR"},
+		gomock.Any()).SetArg(2, catalog).Return(nil)
+
+	prices, _ := testHosting.ListPrices(hosting.Region{ID: regionstr}, "eur")
+	diskprice := catalog[2].UnitPrice[0].Price / hosting.HoursPerMonth
+	expected := hosting.PriceList{
+		RegionID: regionstr,
+		Currency: "EUR",
+		Prices: map[string]hosting.Price{
+			hosting.ProductCores:     {Product: hosting.ProductCores, Hourly: 0.01},
+			hosting.ProductMemory:    {Product: hosting.ProductMemory, Hourly: 0.00001},
+			hosting.ProductDisk:      {Product: hosting.ProductDisk, Hourly: diskprice},
+			hosting.ProductIPv4:      {Product: hosting.ProductIPv4, Hourly: 0.002},
+			hosting.ProductIPv6:      {Product: hosting.ProductIPv6, Hourly: 0},
+			hosting.ProductBandwidth: {Product: hosting.ProductBandwidth, Hourly: 0},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, prices) {
+		t.Errorf("Error, expected %+v, got instead %+v", expected, prices)
+	}
+}
+
+func TestEstimateVM(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	// Prices are only requested once per Region
+	mockClient.EXPECT().Send("catalog.list",
+		[]interface{}{map[string]int{"datacenter_id": region}, "EUR"},
+		gomock.Any()).SetArg(2, catalog).Return(nil).Times(1)
+
+	estimator := hosting.NewEstimator(testHosting, "EUR")
+	vmspec := hosting.VMSpec{RegionID: regionstr, Hostname: vmname, Cores: 2, Memory: 1024}
+	estimate, err := estimator.EstimateVM(vmspec, 20, hosting.IPv4)
+	if err != nil {
+		t.Fatalf("Error, %s", err)
+	}
+	if len(estimate.Items) != 5 {
+		t.Errorf("Error, expected 5 items, got instead %+v", estimate.Items)
+	}
+	expected := 2*0.01 + 1024*0.00001 + 0.002 + 20*0.073/hosting.HoursPerMonth
+	if diff := estimate.Hourly - expected; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Error, expected %f per hour, got instead %f", expected, estimate.Hourly)
+	}
+
+	_, err = estimator.EstimateDisk(hosting.DiskSpec{RegionID: regionstr, Size: 50})
+	if err != nil {
+		t.Errorf("Error, %s", err)
+	}
+}
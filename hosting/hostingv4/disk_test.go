@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/PabloPie/go-gandi/client"
 	"github.com/PabloPie/go-gandi/hosting"
@@ -29,11 +30,11 @@ var (
 )
 
 var disks = []diskv4{
-	{1, "sys_disk1", 10240, 4, "created", "data", []int{1}, true},
-	{4, "sys_disk3", 10240, 4, "created", "data", []int{3}, true},
-	{2, "sys_disk2", 20480, 3, "created", "data", []int{2}, true},
-	{3, "disk3", 10240, 3, "created", "data", []int{2}, false},
-	{5, diskname, 10240, 3, "created", "data", []int{}, false},
+	{ID: 1, Name: "sys_disk1", Size: 10240, RegionID: 4, State: "created", Type: "data", VM: []int{1}, BootDisk: true},
+	{ID: 4, Name: "sys_disk3", Size: 10240, RegionID: 4, State: "created", Type: "data", VM: []int{3}, BootDisk: true},
+	{ID: 2, Name: "sys_disk2", Size: 20480, RegionID: 3, State: "created", Type: "data", VM: []int{2}, BootDisk: true},
+	{ID: 3, Name: "disk3", Size: 10240, RegionID: 3, State: "created", Type: "data", VM: []int{2}, BootDisk: false},
+	{ID: 5, Name: diskname, Size: 10240, RegionID: 3, State: "created", Type: "data", VM: []int{}, BootDisk: false},
 }
 
 func TestCreateDiskWithNameSizeAndRegion(t *testing.T) {
@@ -60,7 +61,7 @@ func TestCreateDiskWithNameSizeAndRegion(t *testing.T) {
 		paramsWait, gomock.Any()).SetArg(2, responseWait).Return(nil).After(creation)
 
 	paramsDiskInfo := []interface{}{responseDiskCreate.DiskID}
-	responseDiskInfo := diskv4{diskid, diskname, disksizeMB, region, "created", "data", []int{}, false}
+	responseDiskInfo := diskv4{ID: diskid, Name: diskname, Size: disksizeMB, RegionID: region, State: "created", Type: "data", VM: []int{}, BootDisk: false}
 	mockClient.EXPECT().Send("hosting.disk.info",
 		paramsDiskInfo, gomock.Any()).SetArg(2, responseDiskInfo).Return(nil).After(wait)
 
@@ -109,7 +110,7 @@ func TestCreateDiskFromImageWithoutSize(t *testing.T) {
 		paramsWait, gomock.Any()).SetArg(2, responseWait).Return(nil).After(creation)
 
 	paramsDiskInfo := []interface{}{responseDiskCreate.DiskID}
-	responseDiskInfo := diskv4{diskid, diskname, 3072, region, "created", "data", []int{}, false}
+	responseDiskInfo := diskv4{ID: diskid, Name: diskname, Size: 3072, RegionID: region, State: "created", Type: "data", VM: []int{}, BootDisk: false}
 	mockClient.EXPECT().Send("hosting.disk.info",
 		paramsDiskInfo, gomock.Any()).SetArg(2, responseDiskInfo).Return(nil).After(wait)
 
@@ -215,7 +216,7 @@ func TestDiskFromName(t *testing.T) {
 	mockClient.EXPECT().Send("hosting.disk.list",
 		paramsDiskInfo, gomock.Any()).SetArg(2, responseDiskInfo).Return(nil)
 
-	disk := testHosting.DiskFromName(diskname)
+	disk, _ := testHosting.DiskFromName(diskname)
 
 	if disk.Name != diskname {
 		t.Errorf("Error, expected to get hosting.Disk with name '%s', got '%s' instead",
@@ -223,6 +224,25 @@ func TestDiskFromName(t *testing.T) {
 	}
 }
 
+func TestDiskFromNameNotFound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	paramsDiskInfo := []interface{}{map[string]interface{}{
+		"name": "nodisk",
+	}}
+	mockClient.EXPECT().Send("hosting.disk.list",
+		paramsDiskInfo, gomock.Any()).SetArg(2, []diskv4{}).Return(nil)
+
+	_, err := testHosting.DiskFromName("nodisk")
+
+	if err != hosting.ErrDiskNotFound {
+		t.Errorf("Error, expected %v, got instead %v", hosting.ErrDiskNotFound, err)
+	}
+}
+
 func TestDeleteDisk(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -456,3 +476,133 @@ func TestDiskFunctionsBadID(t *testing.T) {
 	}
 
 }
+
+func TestListKernels(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	response := map[string][]string{
+		"linux-hvm": {"3.12-x86_64 (hvm)", "4.9-x86_64 (hvm)"},
+		"raw":       {"raw"},
+	}
+	mockClient.EXPECT().Send("hosting.disk.list_kernels",
+		[]interface{}{region}, gomock.Any()).SetArg(2, response).Return(nil)
+
+	kernels, _ := testHosting.ListKernels(hosting.Region{ID: regionstr})
+	expected := []string{"3.12-x86_64 (hvm)", "4.9-x86_64 (hvm)", "raw"}
+
+	if !reflect.DeepEqual(kernels, expected) {
+		t.Errorf("Error, expected %+v, got instead %+v", expected, kernels)
+	}
+}
+
+func TestUpdateDiskKernel(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	kernel := "4.9-x86_64 (hvm)"
+	responseKernels := map[string][]string{"linux-hvm": {"3.12-x86_64 (hvm)", kernel}}
+	list := mockClient.EXPECT().Send("hosting.disk.list_kernels",
+		[]interface{}{region}, gomock.Any()).SetArg(2, responseKernels).Return(nil)
+
+	paramsUpdate := []interface{}{diskid, map[string]string{"kernel": kernel}}
+	responseUpdate := Operation{ID: 1, DiskID: diskid}
+	update := mockClient.EXPECT().Send("hosting.disk.update",
+		paramsUpdate, gomock.Any()).SetArg(2, responseUpdate).Return(nil).After(list)
+
+	responseWait := operationInfo{responseUpdate.ID, "DONE"}
+	wait := mockClient.EXPECT().Send("operation.info",
+		[]interface{}{responseUpdate.ID}, gomock.Any()).SetArg(2, responseWait).Return(nil).After(update)
+
+	responseDiskInfo := diskv4{ID: diskid, Name: diskname, Size: disksizeMB, RegionID: region,
+		State: "created", Type: "data", VM: []int{}, Kernel: kernel}
+	mockClient.EXPECT().Send("hosting.disk.info",
+		[]interface{}{diskid}, gomock.Any()).SetArg(2, responseDiskInfo).Return(nil).After(wait)
+
+	disk, _ := testHosting.UpdateDiskKernel(hosting.Disk{ID: diskidstr, RegionID: regionstr}, kernel)
+
+	if disk.Kernel != kernel {
+		t.Errorf("Error, expected kernel %s, got instead %+v", kernel, disk)
+	}
+}
+
+func TestUpdateDiskKernelNotAvailable(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	responseKernels := map[string][]string{"linux-hvm": {"3.12-x86_64 (hvm)"}}
+	mockClient.EXPECT().Send("hosting.disk.list_kernels",
+		[]interface{}{region}, gomock.Any()).SetArg(2, responseKernels).Return(nil)
+
+	_, err := testHosting.UpdateDiskKernel(hosting.Disk{ID: diskidstr, RegionID: regionstr}, "2.6-x86_64")
+	expected := &HostingError{"UpdateDiskKernel", "-", "kernel", ErrNotAvailable}
+
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("Error, expected %+v, got instead %+v", expected, err)
+	}
+}
+
+func TestListDiskWithTagsInFilter(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	// Tags are filtered locally
+	responseDiskList := []diskv4{
+		{ID: 1, Name: "data--env_prod", RegionID: region},
+		{ID: 2, Name: "data--env_dev", RegionID: region},
+		{ID: 3, Name: "data", RegionID: region},
+	}
+	mockClient.EXPECT().Send("hosting.disk.list",
+		[]interface{}{}, gomock.Any()).SetArg(2, responseDiskList).Return(nil)
+
+	diskfilter := hosting.DiskFilter{Tags: hosting.Tags{"env": "prod"}}
+	disks, err := testHosting.ListDisks(diskfilter)
+	if err != nil || len(disks) != 1 || disks[0].ID != "1" {
+		t.Errorf("Error, expected Disk 1, got instead %+v (%v)", disks, err)
+	}
+}
+
+func TestListDiskWithListsInFilter(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	june := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	paramsDiskList := []interface{}{map[string]interface{}{
+		"id":    []int{1, 2},
+		"name":  "~data*",
+		"state": "created",
+	}}
+	// Dates are filtered locally
+	responseDiskList := []diskv4{
+		{ID: 1, Name: "data1", RegionID: region, State: "created", DateCreated: june},
+		{ID: 2, Name: "data2", RegionID: region, State: "created", DateCreated: june.AddDate(-1, 0, 0)},
+	}
+	mockClient.EXPECT().Send("hosting.disk.list",
+		paramsDiskList, gomock.Any()).SetArg(2, responseDiskList).Return(nil)
+
+	diskfilter := hosting.DiskFilter{
+		IDs:     []string{"1", "2"},
+		Name:    "data*",
+		States:  []string{"created"},
+		Created: hosting.DateRange{After: june.AddDate(0, -1, 0)},
+	}
+	disks, err := testHosting.ListDisks(diskfilter)
+	if err != nil || len(disks) != 1 || disks[0].ID != "1" {
+		t.Errorf("Error, expected Disk 1, got instead %+v (%v)", disks, err)
+	}
+
+	_, err = testHosting.ListDisks(hosting.DiskFilter{IDs: []string{"1", "a"}})
+	if err == nil {
+		t.Errorf("Error, expected error for an invalid ID")
+	}
+}
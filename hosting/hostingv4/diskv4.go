@@ -2,33 +2,47 @@ package hostingv4
 
 import (
 	"log"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/PabloPie/go-gandi/hosting"
 )
 
 type diskv4 struct {
-	ID       int    `xmlrpc:"id"`
-	Name     string `xmlrpc:"name"`
-	Size     int    `xmlrpc:"size"`
-	RegionID int    `xmlrpc:"datacenter_id"`
-	State    string `xmlrpc:"state"`
-	Type     string `xmlrpc:"type"`
-	VM       []int  `xmlrpc:"vms_id"`
-	BootDisk bool   `xmlrpc:"is_boot_disk"`
+	ID          int       `xmlrpc:"id"`
+	Name        string    `xmlrpc:"name"`
+	Size        int       `xmlrpc:"size"`
+	RegionID    int       `xmlrpc:"datacenter_id"`
+	State       string    `xmlrpc:"state"`
+	Type        string    `xmlrpc:"type"`
+	VM          []int     `xmlrpc:"vms_id"`
+	BootDisk    bool      `xmlrpc:"is_boot_disk"`
+	Kernel      string    `xmlrpc:"kernel_version"`
+	DateCreated time.Time `xmlrpc:"date_created"`
 }
 
 type diskSpecv4 struct {
 	RegionID int    `xmlrpc:"datacenter_id"`
 	Name     string `xmlrpc:"name"`
 	Size     int    `xmlrpc:"size"` // in MB
+	Type     string `xmlrpc:"type"`
 }
 
 type diskFilterv4 struct {
-	ID       int    `xmlrpc:"id"`
-	RegionID int    `xmlrpc:"datacenter_id"`
-	Name     string `xmlrpc:"name"`
-	VMID     int    `xmlrpc:"vm_id"`
+	ID       interface{} `xmlrpc:"id"`
+	RegionID interface{} `xmlrpc:"datacenter_id"`
+	Name     string      `xmlrpc:"name"`
+	VMID     int         `xmlrpc:"vm_id"`
+	State    interface{} `xmlrpc:"state"`
+	SortBy   string      `xmlrpc:"sort_by"`
+}
+
+// Fields of the API to sort Disks by
+var diskSortKeysv4 = map[string]string{
+	hosting.SortByName: "name",
+	hosting.SortByDate: "date_created",
+	hosting.SortBySize: "size",
 }
 
 // CreateDisk creates a new empty data disk
@@ -107,16 +121,17 @@ func (h Hostingv4) ListAllDisks() ([]hosting.Disk, error) {
 
 // DiskFromName is a helper function to get a Disk given its name
 //
-// If the Disk does not exist or an error occurred it returns an empty hosting.Disk,
-// use ListDisks with an appropriate DiskFilter to get more details
-// on the possible errors
-func (h Hostingv4) DiskFromName(name string) hosting.Disk {
+// If the Disk does not exist hosting.ErrDiskNotFound is returned
+func (h Hostingv4) DiskFromName(name string) (hosting.Disk, error) {
 	disks, err := h.ListDisks(hosting.DiskFilter{Name: name})
-	if err != nil || len(disks) < 1 {
-		return hosting.Disk{}
+	if err != nil {
+		return hosting.Disk{}, err
+	}
+	if len(disks) < 1 {
+		return hosting.Disk{}, hosting.ErrDiskNotFound
 	}
 
-	return disks[0]
+	return disks[0], nil
 }
 
 // ListDisks returns a list of disks filtered with the options provided in `diskFilter`
@@ -140,9 +155,14 @@ func (h Hostingv4) ListDisks(diskfilter hosting.DiskFilter) ([]hosting.Disk, err
 
 	var disks []hosting.Disk
 	for _, disk := range response {
-		disks = append(disks, fromDiskv4(disk))
-	}
-	return disks, nil
+		res := fromDiskv4(disk)
+		if !diskfilter.Match(res) {
+			continue
+		}
+		disks = append(disks, res)
+	}
+	// The order of the API is kept for equal keys
+	return disks, hosting.SortDisks(disks, diskfilter.Options)
 }
 
 // DeleteDisk deletes the Disk `disk`
@@ -224,6 +244,74 @@ func (h Hostingv4) RenameDisk(disk hosting.Disk, newName string) (hosting.Disk,
 	return h.diskFromID(response.DiskID)
 }
 
+// ListKernels returns the kernels available in `region`, sorted by name
+func (h Hostingv4) ListKernels(region hosting.Region) ([]string, error) {
+	var fn = "ListKernels"
+	if region.ID == "" {
+		return nil, &HostingError{fn, "Region", "ID", ErrNotProvided}
+	}
+	regionid, err := strconv.Atoi(region.ID)
+	if err != nil {
+		return nil, &HostingError{fn, "Region", "ID", ErrParse}
+	}
+
+	// Kernels are grouped by family, e.g. linux, linux-hvm, raw
+	response := map[string][]string{}
+	params := []interface{}{regionid}
+	err = h.Send("hosting.disk.list_kernels", params, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	var kernels []string
+	for _, family := range response {
+		kernels = append(kernels, family...)
+	}
+	sort.Strings(kernels)
+	return kernels, nil
+}
+
+// UpdateDiskKernel changes the kernel of `disk` to `kernel`
+//
+// The kernel is checked against the ones available in
+// the Region of the disk before updating it
+func (h Hostingv4) UpdateDiskKernel(disk hosting.Disk, kernel string) (hosting.Disk, error) {
+	var fn = "UpdateDiskKernel"
+	if disk.ID == "" {
+		return hosting.Disk{}, &HostingError{fn, "Disk", "ID", ErrNotProvided}
+	}
+	if kernel == "" {
+		return hosting.Disk{}, &HostingError{fn, "-", "kernel", ErrNotProvided}
+	}
+	diskid, err := strconv.Atoi(disk.ID)
+	if err != nil {
+		return hosting.Disk{}, &HostingError{fn, "Disk", "ID", ErrParse}
+	}
+
+	kernels, err := h.ListKernels(hosting.Region{ID: disk.RegionID})
+	if err != nil {
+		return hosting.Disk{}, err
+	}
+	i := sort.SearchStrings(kernels, kernel)
+	if i == len(kernels) || kernels[i] != kernel {
+		return hosting.Disk{}, &HostingError{fn, "-", "kernel", ErrNotAvailable}
+	}
+
+	response := Operation{}
+	diskupdate := map[string]string{"kernel": kernel}
+	request := []interface{}{diskid, diskupdate}
+	log.Printf("[INFO] Updating kernel of Disk %s to %s...", disk.ID, kernel)
+	err = h.Send("hosting.disk.update", request, &response)
+	if err != nil {
+		return hosting.Disk{}, err
+	}
+	if err := h.waitForOp(response); err != nil {
+		return hosting.Disk{}, err
+	}
+
+	return h.diskFromID(response.DiskID)
+}
+
 // Helper functions
 
 // Obtain a Hosting hosting.Disk from an integer ID (v4 representation)
@@ -255,13 +343,13 @@ func toDiskSpecv4(disk hosting.DiskSpec) (diskSpecv4, error) {
 
 // Hosting DiskFilter -> v4 DiskFilter
 func toDiskFilterv4(disk hosting.DiskFilter) (diskFilterv4, error) {
-	region := toInt(disk.RegionID)
-	if region == -1 {
+	region, ok := toIDFilter(disk.RegionID, disk.RegionIDs)
+	if !ok {
 		return diskFilterv4{}, internalParseError("DiskFilter", "RegionID")
 	}
 
-	id := toInt(disk.ID)
-	if id == -1 {
+	id, ok := toIDFilter(disk.ID, disk.IDs)
+	if !ok {
 		return diskFilterv4{}, internalParseError("DiskFilter", "ID")
 	}
 
@@ -269,11 +357,19 @@ func toDiskFilterv4(disk hosting.DiskFilter) (diskFilterv4, error) {
 	if vmid == -1 {
 		return diskFilterv4{}, internalParseError("DiskFilter", "VMID")
 	}
+
+	sortby, ok := toSortByv4(disk.Options, diskSortKeysv4)
+	if !ok {
+		return diskFilterv4{}, &HostingError{"ListDisks", "DiskFilter", "Options", ErrNotAvailable}
+	}
+
 	return diskFilterv4{
 		RegionID: region,
 		ID:       id,
 		VMID:     vmid,
-		Name:     disk.Name,
+		Name:     toNameFilter(disk.Name),
+		State:    toStringFilter("", disk.States),
+		SortBy:   sortby,
 	}, nil
 }
 
@@ -287,13 +383,15 @@ func fromDiskv4(disk diskv4) hosting.Disk {
 		vms = append(vms, vm)
 	}
 	return hosting.Disk{
-		ID:       id,
-		Name:     disk.Name,
-		Size:     disk.Size / 1024,
-		RegionID: region,
-		State:    disk.State,
-		Type:     disk.Type,
-		VM:       vms,
-		BootDisk: disk.BootDisk,
+		ID:          id,
+		Name:        disk.Name,
+		Size:        disk.Size / 1024,
+		RegionID:    region,
+		State:       disk.State,
+		Type:        disk.Type,
+		VM:          vms,
+		BootDisk:    disk.BootDisk,
+		Kernel:      disk.Kernel,
+		DateCreated: disk.DateCreated,
 	}
 }
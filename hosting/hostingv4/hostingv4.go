@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/PabloPie/go-gandi/client"
+	"github.com/PabloPie/go-gandi/hosting"
 )
 
 var (
@@ -21,6 +22,10 @@ var (
 	// for example when working when distinct objects that have to be in the
 	// same datacenter
 	ErrMismatch = errors.New("Value mismatch")
+
+	// ErrNotAvailable indicates that a value is not offered by the API,
+	// for example a kernel that does not exist in the datacenter of a disk
+	ErrNotAvailable = errors.New("Not available")
 )
 
 // A Hostingv4 contains an xmlrpc client to send requests to
@@ -102,3 +107,69 @@ func toInt(str string) (num int) {
 	}
 	return
 }
+
+// toIDFilter returns the value of a filter on IDs given a single
+// value and a list: nil if none is set, an int for one value and
+// a list otherwise, false is returned if an ID is not a number
+//
+// When both are set only the single value is sent, the list is
+// checked by the client with the Match method of the filter
+func toIDFilter(single string, list []string) (interface{}, bool) {
+	if single != "" {
+		id := toInt(single)
+		return id, id != -1
+	}
+	if len(list) == 0 {
+		return nil, true
+	}
+	ids := make([]int, len(list))
+	for i, s := range list {
+		if ids[i] = toInt(s); ids[i] <= 0 {
+			return nil, false
+		}
+	}
+	if len(ids) == 1 {
+		return ids[0], true
+	}
+	return ids, true
+}
+
+// toStringFilter is toIDFilter for string values
+func toStringFilter(single string, list []string) interface{} {
+	if single != "" {
+		return single
+	}
+	switch len(list) {
+	case 0:
+		return nil
+	case 1:
+		return list[0]
+	}
+	return list
+}
+
+// toSortByv4 returns the sort_by option of a list from the field
+// of the API `keys` maps the key of `opts` to, '-' is prepended to
+// sort in descending order
+func toSortByv4(opts hosting.ListOptions, keys map[string]string) (string, bool) {
+	if opts.SortBy == "" {
+		return "", true
+	}
+	field, ok := keys[opts.SortBy]
+	if !ok {
+		return "", false
+	}
+	if opts.Descending {
+		return "-" + field, true
+	}
+	return field, true
+}
+
+// toNameFilter returns the value of a filter on names, the API
+// matches the '*' of the names prefixed with '~'
+func toNameFilter(name string) string {
+	if hosting.IsPattern(name) {
+		return "~" + name
+	}
+	return name
+}
@@ -0,0 +1,227 @@
+package hostingv4
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/PabloPie/go-gandi/hosting"
+)
+
+type ifaceFilterv4 struct {
+	ID       int    `xmlrpc:"id"`
+	RegionID int    `xmlrpc:"datacenter_id"`
+	VMID     int    `xmlrpc:"vm_id"`
+	Type     string `xmlrpc:"type"`
+}
+
+// ListInterfaces returns a list of interfaces filtered with the options provided in `ifacefilter`
+func (h Hostingv4) ListInterfaces(ifacefilter hosting.InterfaceFilter) ([]hosting.Interface, error) {
+	filterv4, err := toIfaceFilterv4(ifacefilter)
+	if err != nil {
+		return nil, err
+	}
+	filter, _ := structToMap(filterv4)
+
+	response := []iface{}
+	params := []interface{}{}
+	if len(filter) > 0 {
+		params = append(params, filter)
+	}
+	err = h.Send("hosting.iface.list", params, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	var ifaces []hosting.Interface
+	for _, i := range response {
+		ifaces = append(ifaces, fromIfacev4(i))
+	}
+	return ifaces, nil
+}
+
+// InterfaceFromIP returns the interface the IP `ip` belongs to
+func (h Hostingv4) InterfaceFromIP(ip hosting.IPAddress) (hosting.Interface, error) {
+	var fn = "InterfaceFromIP"
+	if ip.ID == "" {
+		return hosting.Interface{}, &HostingError{fn, "IPAddress", "ID", ErrNotProvided}
+	}
+	ipid, err := strconv.Atoi(ip.ID)
+	if err != nil {
+		return hosting.Interface{}, &HostingError{fn, "IPAddress", "ID", ErrParse}
+	}
+
+	ifaceid, err := h.ifaceIDFromIPID(ipid)
+	if err != nil {
+		return hosting.Interface{}, err
+	}
+	return h.ifaceFromID(ifaceid)
+}
+
+// UpdateInterfaceBandwidth sets the bandwidth of `ifc` to `bandwidth`, in kbps
+func (h Hostingv4) UpdateInterfaceBandwidth(ifc hosting.Interface, bandwidth float32) (hosting.Interface, error) {
+	var fn = "UpdateInterfaceBandwidth"
+	if ifc.ID == "" {
+		return hosting.Interface{}, &HostingError{fn, "Interface", "ID", ErrNotProvided}
+	}
+	ifaceid, err := strconv.Atoi(ifc.ID)
+	if err != nil {
+		return hosting.Interface{}, &HostingError{fn, "Interface", "ID", ErrParse}
+	}
+
+	if err = h.updateIfaceBandwidth(ifaceid, bandwidth); err != nil {
+		return hosting.Interface{}, err
+	}
+
+	return h.ifaceFromID(ifaceid)
+}
+
+// AttachIPToInterface attaches an existing IP to `ifc`, both objects
+// must be in the same hosting.Region
+func (h Hostingv4) AttachIPToInterface(ifc hosting.Interface, ip hosting.IPAddress) (hosting.Interface, error) {
+	var fn = "AttachIPToInterface"
+	if ifc.RegionID != ip.RegionID {
+		return hosting.Interface{}, &HostingError{fn, "Interface/IPAddress", "RegionID", ErrMismatch}
+	}
+	ifaceid, err := strconv.Atoi(ifc.ID)
+	if err != nil {
+		return hosting.Interface{}, internalParseError("Interface", "ID")
+	}
+	ipid, err := strconv.Atoi(ip.ID)
+	if err != nil {
+		return hosting.Interface{}, internalParseError("IPAddress", "ID")
+	}
+
+	response := Operation{}
+	err = h.Send("hosting.ip.attach", []interface{}{ipid, ifaceid}, &response)
+	if err != nil {
+		return hosting.Interface{}, err
+	}
+	if err = h.waitForOp(response); err != nil {
+		return hosting.Interface{}, err
+	}
+
+	return h.ifaceFromID(ifaceid)
+}
+
+// MoveInterface attaches `ifc` to `vm`, detaching it first from
+// the VM it is currently attached to
+//
+// If `ifc` is already attached to `vm` nothing is done
+func (h Hostingv4) MoveInterface(ifc hosting.Interface, vm hosting.VM) (hosting.Interface, error) {
+	var fn = "MoveInterface"
+	if ifc.RegionID != vm.RegionID {
+		return hosting.Interface{}, &HostingError{fn, "Interface/VM", "RegionID", ErrMismatch}
+	}
+	ifaceid, err := strconv.Atoi(ifc.ID)
+	if err != nil {
+		return hosting.Interface{}, internalParseError("Interface", "ID")
+	}
+	vmid, err := strconv.Atoi(vm.ID)
+	if err != nil {
+		return hosting.Interface{}, internalParseError("VM", "ID")
+	}
+
+	// The interface given might be outdated, ask the API
+	// where it is currently attached
+	current := iface{}
+	err = h.Send("hosting.iface.info", []interface{}{ifaceid}, &current)
+	if err != nil {
+		return hosting.Interface{}, err
+	}
+	if current.VMID == vmid {
+		return fromIfacev4(current), nil
+	}
+
+	response := Operation{}
+	if current.VMID != 0 {
+		log.Printf("[INFO] Detaching Interface %d from VM %d...", ifaceid, current.VMID)
+		err = h.Send("hosting.vm.iface_detach", []interface{}{current.VMID, ifaceid}, &response)
+		if err != nil {
+			return hosting.Interface{}, err
+		}
+		if err = h.waitForOp(response); err != nil {
+			return hosting.Interface{}, err
+		}
+	}
+
+	log.Printf("[INFO] Attaching Interface %d to VM %d...", ifaceid, vmid)
+	err = h.Send("hosting.vm.iface_attach", []interface{}{vmid, ifaceid}, &response)
+	if err != nil {
+		return hosting.Interface{}, err
+	}
+	if err = h.waitForOp(response); err != nil {
+		return hosting.Interface{}, err
+	}
+
+	return h.ifaceFromID(ifaceid)
+}
+
+// Helper functions
+
+// ifaceFromID returns a global hosting.Interface from a v4 id
+func (h Hostingv4) ifaceFromID(ifaceid int) (hosting.Interface, error) {
+	response := iface{}
+	err := h.Send("hosting.iface.info", []interface{}{ifaceid}, &response)
+	if err != nil {
+		return hosting.Interface{}, err
+	}
+	return fromIfacev4(response), nil
+}
+
+// updateIfaceBandwidth updates the bandwidth of an interface
+// and waits for the operation to finish
+func (h Hostingv4) updateIfaceBandwidth(ifaceid int, bandwidth float32) error {
+	ifaceupdate := map[string]interface{}{"bandwidth": bandwidth}
+	response := Operation{}
+	request := []interface{}{ifaceid, ifaceupdate}
+	err := h.Send("hosting.iface.update", request, &response)
+	if err != nil {
+		return err
+	}
+	return h.waitForOp(response)
+}
+
+// Conversion functions
+
+// Hosting InterfaceFilter -> v4 InterfaceFilter
+func toIfaceFilterv4(ifacefilter hosting.InterfaceFilter) (ifaceFilterv4, error) {
+	region := toInt(ifacefilter.RegionID)
+	if region == -1 {
+		return ifaceFilterv4{}, internalParseError("InterfaceFilter", "RegionID")
+	}
+
+	id := toInt(ifacefilter.ID)
+	if id == -1 {
+		return ifaceFilterv4{}, internalParseError("InterfaceFilter", "ID")
+	}
+
+	vmid := toInt(ifacefilter.VMID)
+	if vmid == -1 {
+		return ifaceFilterv4{}, internalParseError("InterfaceFilter", "VMID")
+	}
+
+	return ifaceFilterv4{
+		ID:       id,
+		RegionID: region,
+		VMID:     vmid,
+		Type:     ifacefilter.Type,
+	}, nil
+}
+
+// v4 iface -> Hosting Interface
+func fromIfacev4(i iface) hosting.Interface {
+	var vlan string
+	if i.Vlan.ID != 0 {
+		vlan = strconv.Itoa(i.Vlan.ID)
+	}
+	return hosting.Interface{
+		ID:        strconv.Itoa(i.ID),
+		RegionID:  strconv.Itoa(i.RegionID),
+		VM:        strconv.Itoa(i.VMID),
+		Bandwidth: i.Bandwidth,
+		IPs:       ipsFromIface(i),
+		Type:      i.Type,
+		Vlan:      vlan,
+		State:     i.State,
+	}
+}
@@ -0,0 +1,138 @@
+package hostingv4
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/PabloPie/go-gandi/hosting"
+	"github.com/PabloPie/go-gandi/mock"
+	"github.com/golang/mock/gomock"
+)
+
+var ifacesv4 = []iface{
+	{ID: 10, RegionID: 123, VMID: 1, Bandwidth: 102400, Type: "public", State: "used",
+		IPs: []iPAddressv4{{ID: 100, IP: "192.168.0.1", RegionID: 123, Version: 4, VM: 1, State: "used"}}},
+	{ID: 11, RegionID: 123, VMID: 0, Bandwidth: 204800, Type: "public", State: "free",
+		IPs: []iPAddressv4{{ID: 102, IP: "2001:4b98::DEAD", RegionID: 123, Version: 6, VM: 0, State: "free"}}},
+}
+
+func TestListInterfacesByVMID(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	paramsIfaceList := []interface{}{map[string]interface{}{"vm_id": 1}}
+	mockClient.EXPECT().Send("hosting.iface.list",
+		paramsIfaceList, gomock.Any()).SetArg(2, ifacesv4[:1]).Return(nil)
+
+	ifaces, _ := testHosting.ListInterfaces(hosting.InterfaceFilter{VMID: "1"})
+
+	expected := []hosting.Interface{{
+		ID:        "10",
+		RegionID:  "123",
+		VM:        "1",
+		Bandwidth: 102400,
+		Type:      "public",
+		State:     "used",
+		IPs: []hosting.IPAddress{{ID: "100", IP: "192.168.0.1", RegionID: "123",
+			Version: hosting.IPv4, VM: "1", State: "used"}},
+	}}
+	if !reflect.DeepEqual(ifaces, expected) {
+		t.Errorf("Error, expected %+v, got instead %+v", expected, ifaces)
+	}
+}
+
+func TestListInterfacesBadFilter(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	_, err := testHosting.ListInterfaces(hosting.InterfaceFilter{VMID: "notanint"})
+	if err == nil {
+		t.Errorf("Error, VMID given was not an int, expected error")
+	}
+}
+
+func TestUpdateInterfaceBandwidth(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	paramsIfaceUpdate := []interface{}{11, map[string]interface{}{"bandwidth": float32(204800)}}
+	responseIfaceUpdate := Operation{ID: 5, IfaceID: 11}
+	update := mockClient.EXPECT().Send("hosting.iface.update",
+		paramsIfaceUpdate, gomock.Any()).SetArg(2, responseIfaceUpdate).Return(nil)
+
+	paramsWait := []interface{}{responseIfaceUpdate.ID}
+	responseWait := operationInfo{responseIfaceUpdate.ID, "DONE"}
+	wait := mockClient.EXPECT().Send("operation.info",
+		paramsWait, gomock.Any()).SetArg(2, responseWait).Return(nil).After(update)
+
+	mockClient.EXPECT().Send("hosting.iface.info",
+		[]interface{}{11}, gomock.Any()).SetArg(2, ifacesv4[1]).Return(nil).After(wait)
+
+	ifc, _ := testHosting.UpdateInterfaceBandwidth(hosting.Interface{ID: "11"}, 204800)
+
+	if ifc.Bandwidth != 204800 {
+		t.Errorf("Error, expected bandwidth to be %v, got instead %v", 204800, ifc.Bandwidth)
+	}
+}
+
+func TestMoveInterface(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	info := mockClient.EXPECT().Send("hosting.iface.info",
+		[]interface{}{10}, gomock.Any()).SetArg(2, ifacesv4[0]).Return(nil)
+
+	responseDetach := Operation{ID: 5, IfaceID: 10, VMID: 1}
+	detach := mockClient.EXPECT().Send("hosting.vm.iface_detach",
+		[]interface{}{1, 10}, gomock.Any()).SetArg(2, responseDetach).Return(nil).After(info)
+
+	waitDetach := mockClient.EXPECT().Send("operation.info",
+		[]interface{}{responseDetach.ID}, gomock.Any()).
+		SetArg(2, operationInfo{responseDetach.ID, "DONE"}).Return(nil).After(detach)
+
+	responseAttach := Operation{ID: 6, IfaceID: 10, VMID: 2}
+	attach := mockClient.EXPECT().Send("hosting.vm.iface_attach",
+		[]interface{}{2, 10}, gomock.Any()).SetArg(2, responseAttach).Return(nil).After(waitDetach)
+
+	waitAttach := mockClient.EXPECT().Send("operation.info",
+		[]interface{}{responseAttach.ID}, gomock.Any()).
+		SetArg(2, operationInfo{responseAttach.ID, "DONE"}).Return(nil).After(attach)
+
+	moved := ifacesv4[0]
+	moved.VMID = 2
+	mockClient.EXPECT().Send("hosting.iface.info",
+		[]interface{}{10}, gomock.Any()).SetArg(2, moved).Return(nil).After(waitAttach)
+
+	ifc := hosting.Interface{ID: "10", RegionID: "123"}
+	vm := hosting.VM{ID: "2", RegionID: "123"}
+	ifcres, err := testHosting.MoveInterface(ifc, vm)
+	if err != nil {
+		t.Errorf("Error, %s", err)
+	}
+
+	if ifcres.VM != "2" {
+		t.Errorf("Error, expected interface to be attached to VM %s, got instead %s", "2", ifcres.VM)
+	}
+}
+
+func TestMoveInterfaceRegionMismatch(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	ifc := hosting.Interface{ID: "10", RegionID: "123"}
+	vm := hosting.VM{ID: "2", RegionID: "456"}
+	_, err := testHosting.MoveInterface(ifc, vm)
+	if err == nil {
+		t.Errorf("Error, expected error for objects in different regions")
+	}
+}
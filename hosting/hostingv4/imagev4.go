@@ -3,18 +3,26 @@ package hostingv4
 import (
 	"errors"
 	"fmt"
+	"log"
 	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/PabloPie/go-gandi/hosting"
 )
 
 // internal representation of a hosting.Disk Image for API v4
 type diskImagev4 struct {
-	ID       int    `xmlrpc:"id"`
-	DiskID   int    `xmlrpc:"disk_id"`
-	RegionID int    `xmlrpc:"datacenter_id"`
-	Name     string `xmlrpc:"label"`
-	Size     int    `xmlrpc:"size"`
+	ID            int       `xmlrpc:"id"`
+	DiskID        int       `xmlrpc:"disk_id"`
+	RegionID      int       `xmlrpc:"datacenter_id"`
+	Name          string    `xmlrpc:"label"`
+	Size          int       `xmlrpc:"size"`
+	Architecture  string    `xmlrpc:"os_arch"`
+	KernelVersion string    `xmlrpc:"kernel_version"`
+	Visibility    string    `xmlrpc:"visibility"`
+	DateCreated   time.Time `xmlrpc:"date_created"`
 }
 
 // ImageByName returns the hosting.DiskImage with label `name` found in `region`
@@ -75,16 +83,179 @@ func (h Hostingv4) ListImagesInRegion(region hosting.Region) ([]hosting.DiskImag
 	return diskimages, nil
 }
 
+// ListImages returns the images matching `imagefilter`, the most recent first
+//
+// Region and visibility are filtered by the API, the rest of the
+// fields of `imagefilter` are matched locally
+func (h Hostingv4) ListImages(imagefilter hosting.ImageFilter) ([]hosting.DiskImage, error) {
+	filter := map[string]interface{}{}
+	if imagefilter.RegionID != "" {
+		regionid, err := strconv.Atoi(imagefilter.RegionID)
+		if err != nil {
+			return nil, internalParseError("ImageFilter", "RegionID")
+		}
+		filter["datacenter_id"] = regionid
+	}
+	if imagefilter.Visibility != "" {
+		filter["visibility"] = imagefilter.Visibility
+	}
+
+	response := []diskImagev4{}
+	params := []interface{}{}
+	if len(filter) > 0 {
+		params = append(params, filter)
+	}
+	err := h.Send("hosting.image.list", params, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	var diskimages []hosting.DiskImage
+	for _, image := range response {
+		diskimage := fromDiskImagev4(image)
+		if imagefilter.Match(diskimage) {
+			diskimages = append(diskimages, diskimage)
+		}
+	}
+	hosting.SortImagesByVersion(diskimages)
+
+	return diskimages, nil
+}
+
+// LatestImage returns the most recent image matching `imagefilter`
+func (h Hostingv4) LatestImage(imagefilter hosting.ImageFilter) (hosting.DiskImage, error) {
+	images, err := h.ListImages(imagefilter)
+	if err != nil {
+		return hosting.DiskImage{}, err
+	}
+	if len(images) < 1 {
+		return hosting.DiskImage{}, errors.New("Image not found")
+	}
+	return images[0], nil
+}
+
+// CreateImageFromDisk creates a private image named `name` from `disk`
+//
+// In v4 private images are disks of type image, the source disk is
+// copied so it can keep being used, it should not be attached to a
+// running hosting.VM while the copy is made
+func (h Hostingv4) CreateImageFromDisk(disk hosting.Disk, name string) (hosting.DiskImage, error) {
+	var fn = "CreateImageFromDisk"
+	if disk.ID == "" {
+		return hosting.DiskImage{}, &HostingError{fn, "Disk", "ID", ErrNotProvided}
+	}
+	if name == "" {
+		return hosting.DiskImage{}, &HostingError{fn, "-", "name", ErrNotProvided}
+	}
+	diskid, err := strconv.Atoi(disk.ID)
+	if err != nil {
+		return hosting.DiskImage{}, &HostingError{fn, "Disk", "ID", ErrParse}
+	}
+	regionid, err := strconv.Atoi(disk.RegionID)
+	if err != nil {
+		return hosting.DiskImage{}, &HostingError{fn, "Disk", "RegionID", ErrParse}
+	}
+
+	imagespec, _ := structToMap(diskSpecv4{
+		RegionID: regionid,
+		Name:     name,
+		Type:     "image",
+	})
+	response := Operation{}
+	params := []interface{}{imagespec, diskid}
+	log.Printf("[INFO] Creating Image %s from Disk %s...", name, disk.ID)
+	err = h.Send("hosting.disk.create_from", params, &response)
+	if err != nil {
+		return hosting.DiskImage{}, err
+	}
+	if err = h.waitForOp(response); err != nil {
+		return hosting.DiskImage{}, err
+	}
+	log.Printf("[INFO] Image %s(ID: %d) created!", name, response.DiskID)
+
+	image := diskv4{}
+	err = h.Send("hosting.disk.info", []interface{}{response.DiskID}, &image)
+	if err != nil {
+		return hosting.DiskImage{}, err
+	}
+	return fromPrivateImagev4(image), nil
+}
+
+// ListPrivateImages returns every private image, that is,
+// every disk of type image
+func (h Hostingv4) ListPrivateImages() ([]hosting.DiskImage, error) {
+	response := []diskv4{}
+	params := []interface{}{map[string]interface{}{"type": "image"}}
+	err := h.Send("hosting.disk.list", params, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []hosting.DiskImage
+	for _, image := range response {
+		images = append(images, fromPrivateImagev4(image))
+	}
+	return images, nil
+}
+
+// DeleteImage deletes a private image
+func (h Hostingv4) DeleteImage(image hosting.DiskImage) error {
+	var fn = "DeleteImage"
+	if image.Visibility != "private" {
+		return &HostingError{fn, "DiskImage", "Visibility", ErrMismatch}
+	}
+	return h.DeleteDisk(hosting.Disk{ID: image.DiskID})
+}
+
 // diskImagev4 -> Hosting hosting.DiskImage
 func fromDiskImagev4(image diskImagev4) hosting.DiskImage {
 	id := strconv.Itoa(image.ID)
 	diskid := strconv.Itoa(image.DiskID)
 	regionid := strconv.Itoa(image.RegionID)
+	os, version := parseImageLabel(image.Name)
 	return hosting.DiskImage{
-		ID:       id,
-		DiskID:   diskid,
-		RegionID: regionid,
-		Name:     image.Name,
-		Size:     image.Size / 1024,
+		ID:            id,
+		DiskID:        diskid,
+		RegionID:      regionid,
+		Name:          image.Name,
+		Size:          image.Size / 1024,
+		OS:            os,
+		Version:       version,
+		Architecture:  image.Architecture,
+		KernelVersion: image.KernelVersion,
+		Visibility:    image.Visibility,
+		DateCreated:   image.DateCreated,
+	}
+}
+
+// v4 Disk of type image -> Hosting hosting.DiskImage
+func fromPrivateImagev4(disk diskv4) hosting.DiskImage {
+	id := strconv.Itoa(disk.ID)
+	os, version := parseImageLabel(disk.Name)
+	return hosting.DiskImage{
+		ID:            id,
+		DiskID:        id,
+		RegionID:      strconv.Itoa(disk.RegionID),
+		Name:          disk.Name,
+		Size:          disk.Size / 1024,
+		OS:            os,
+		Version:       version,
+		KernelVersion: disk.Kernel,
+		Visibility:    "private",
+		DateCreated:   disk.DateCreated,
+	}
+}
+
+// parseImageLabel extracts the OS and its version from an image label
+//
+// v4 labels start with the OS name followed by its version,
+// e.g. "Debian 9 64 bits (HVM)" or "Ubuntu 18.04 64 bits LTS (HVM)"
+func parseImageLabel(label string) (os string, version string) {
+	words := strings.Fields(label)
+	for i, word := range words {
+		if unicode.IsDigit(rune(word[0])) {
+			return strings.Join(words[:i], " "), word
+		}
 	}
+	return label, ""
 }
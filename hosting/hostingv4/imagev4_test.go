@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/PabloPie/go-gandi/hosting"
 	"github.com/PabloPie/go-gandi/mock"
@@ -44,7 +45,7 @@ func TestImageByNameBadRegionID(t *testing.T) {
 	testHosting := Newv4Hosting(mockClient)
 
 	_, err := testHosting.ImageByName("Debian", hosting.Region{ID: "badid"})
-	expected := errors.New("Error parsing RegionID 'badid' from hosting.Region {badid  }")
+	expected := errors.New("Error parsing RegionID 'badid' from hosting.Region {badid      0001-01-01 00:00:00 +0000 UTC}")
 
 	if !reflect.DeepEqual(expected, err) {
 		t.Errorf("Error, expected '%+v', got instead '%+v'", expected, err)
@@ -96,7 +97,7 @@ func TestListImagesInRegionBadRegionID(t *testing.T) {
 	testHosting := Newv4Hosting(mockClient)
 
 	_, err := testHosting.ListImagesInRegion(hosting.Region{ID: "badid"})
-	expected := errors.New("Error parsing RegionID 'badid' from hosting.Region {badid  }")
+	expected := errors.New("Error parsing RegionID 'badid' from hosting.Region {badid      0001-01-01 00:00:00 +0000 UTC}")
 
 	if !reflect.DeepEqual(expected, err) {
 		t.Errorf("Error, expected '%+v', got instead '%+v'", expected, err)
@@ -147,3 +148,206 @@ func TestListImagesInRegionSucceeded(t *testing.T) {
 		t.Errorf("Error, expected '%+v', got instead '%+v'", expected, images)
 	}
 }
+
+/* ListImages */
+
+var imagesCatalog = []diskImagev4{
+	{ID: 1, DiskID: 10, RegionID: 123, Name: "Debian 8 64 bits (HVM)", Size: 3072, Visibility: "all"},
+	{ID: 2, DiskID: 11, RegionID: 123, Name: "Debian 10 64 bits (HVM)", Size: 3072, Visibility: "all"},
+	{ID: 3, DiskID: 12, RegionID: 123, Name: "Debian 9 64 bits (HVM)", Size: 3072, Visibility: "all"},
+	{ID: 4, DiskID: 13, RegionID: 123, Name: "Ubuntu 18.04 64 bits LTS (HVM)", Size: 3072, Visibility: "all"},
+}
+
+func TestParseImageLabel(t *testing.T) {
+	labels := map[string][2]string{
+		"Debian 9 64 bits (HVM)":         {"Debian", "9"},
+		"Ubuntu 18.04 64 bits LTS (HVM)": {"Ubuntu", "18.04"},
+		"Windows Server 2012":            {"Windows Server", "2012"},
+		"MS-DOS":                         {"MS-DOS", ""},
+	}
+	for label, expected := range labels {
+		os, version := parseImageLabel(label)
+		if os != expected[0] || version != expected[1] {
+			t.Errorf("Error, expected %v for '%s', got instead [%s %s]", expected, label, os, version)
+		}
+	}
+}
+
+func TestListImagesByOS(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	mockClient.EXPECT().Send("hosting.image.list",
+		[]interface{}{map[string]interface{}{"datacenter_id": 123}},
+		gomock.Any()).SetArg(2, imagesCatalog).Return(nil)
+
+	images, _ := testHosting.ListImages(hosting.ImageFilter{RegionID: "123", OS: "debian"})
+
+	var names []string
+	for _, image := range images {
+		names = append(names, image.Name)
+	}
+	expected := []string{"Debian 10 64 bits (HVM)", "Debian 9 64 bits (HVM)", "Debian 8 64 bits (HVM)"}
+	if !reflect.DeepEqual(expected, names) {
+		t.Errorf("Error, expected '%+v', got instead '%+v'", expected, names)
+	}
+}
+
+func TestLatestImageFuzzyName(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	mockClient.EXPECT().Send("hosting.image.list",
+		[]interface{}{},
+		gomock.Any()).SetArg(2, imagesCatalog).Return(nil)
+
+	image, _ := testHosting.LatestImage(hosting.ImageFilter{Name: "ubuntu lts"})
+	expected := fromDiskImagev4(imagesCatalog[3])
+
+	if !reflect.DeepEqual(expected, image) {
+		t.Errorf("Error, expected '%+v', got instead '%+v'", expected, image)
+	}
+}
+
+func TestLatestImageNotFound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	mockClient.EXPECT().Send("hosting.image.list",
+		[]interface{}{map[string]interface{}{"datacenter_id": 123}},
+		gomock.Any()).SetArg(2, imagesCatalog).Return(nil)
+
+	_, err := testHosting.LatestImage(hosting.ImageFilter{RegionID: "123", OS: "centos"})
+	expected := errors.New("Image not found")
+
+	if !reflect.DeepEqual(expected, err) {
+		t.Errorf("Error, expected '%+v', got instead '%+v'", expected, err)
+	}
+}
+
+/* Private images */
+
+func TestCreateImageFromDisk(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	paramsCreate := []interface{}{map[string]interface{}{
+		"datacenter_id": region,
+		"name":          "Debian 9 web",
+		"type":          "image",
+	}, diskid}
+	responseCreate := Operation{ID: 1, DiskID: 7}
+	creation := mockClient.EXPECT().Send("hosting.disk.create_from",
+		paramsCreate, gomock.Any()).SetArg(2, responseCreate).Return(nil)
+
+	responseWait := operationInfo{responseCreate.ID, "DONE"}
+	wait := mockClient.EXPECT().Send("operation.info",
+		[]interface{}{responseCreate.ID}, gomock.Any()).SetArg(2, responseWait).Return(nil).After(creation)
+
+	june := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	responseInfo := diskv4{ID: 7, Name: "Debian 9 web", Size: disksizeMB, RegionID: region, State: "created", Type: "image",
+		Kernel: "4.19-x86_64", DateCreated: june}
+	mockClient.EXPECT().Send("hosting.disk.info",
+		[]interface{}{responseCreate.DiskID}, gomock.Any()).SetArg(2, responseInfo).Return(nil).After(wait)
+
+	disk := hosting.Disk{ID: diskidstr, RegionID: regionstr}
+	image, err := testHosting.CreateImageFromDisk(disk, "Debian 9 web")
+	if err != nil {
+		t.Fatalf("Error, %s", err)
+	}
+
+	expected := hosting.DiskImage{
+		ID:            "7",
+		DiskID:        "7",
+		RegionID:      regionstr,
+		Name:          "Debian 9 web",
+		Size:          disksize,
+		OS:            "Debian",
+		Version:       "9",
+		KernelVersion: "4.19-x86_64",
+		Visibility:    "private",
+		DateCreated:   june,
+	}
+	if !reflect.DeepEqual(expected, image) {
+		t.Errorf("Error, expected '%+v', got instead '%+v'", expected, image)
+	}
+}
+
+func TestCreateImageFromDiskNoName(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	_, err := testHosting.CreateImageFromDisk(hosting.Disk{ID: diskidstr, RegionID: regionstr}, "")
+	expected := &HostingError{"CreateImageFromDisk", "-", "name", ErrNotProvided}
+
+	if !reflect.DeepEqual(expected, err) {
+		t.Errorf("Error, expected '%+v', got instead '%+v'", expected, err)
+	}
+}
+
+func TestListPrivateImages(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	response := []diskv4{
+		{ID: 7, Name: "Debian 9 web", Size: 3072, RegionID: region, State: "created", Type: "image"},
+		{ID: 8, Name: "db", Size: 10240, RegionID: region, State: "created", Type: "image"},
+	}
+	mockClient.EXPECT().Send("hosting.disk.list",
+		[]interface{}{map[string]interface{}{"type": "image"}},
+		gomock.Any()).SetArg(2, response).Return(nil)
+
+	images, _ := testHosting.ListPrivateImages()
+
+	if len(images) != 2 {
+		t.Fatalf("Error, expected 2 images, got instead %+v", images)
+	}
+	if images[1].DiskID != "8" || images[1].Visibility != "private" || images[1].Size != 10 {
+		t.Errorf("Error, unexpected image %+v", images[1])
+	}
+}
+
+func TestDeleteImagePublic(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	err := testHosting.DeleteImage(fromDiskImagev4(images4[0]))
+	expected := &HostingError{"DeleteImage", "DiskImage", "Visibility", ErrMismatch}
+
+	if !reflect.DeepEqual(expected, err) {
+		t.Errorf("Error, expected '%+v', got instead '%+v'", expected, err)
+	}
+}
+
+func TestDeleteImage(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	responseDelete := Operation{ID: 1}
+	deletion := mockClient.EXPECT().Send("hosting.disk.delete",
+		[]interface{}{7}, gomock.Any()).SetArg(2, responseDelete).Return(nil)
+	responseWait := operationInfo{responseDelete.ID, "DONE"}
+	mockClient.EXPECT().Send("operation.info",
+		[]interface{}{responseDelete.ID}, gomock.Any()).SetArg(2, responseWait).Return(nil).After(deletion)
+
+	err := testHosting.DeleteImage(hosting.DiskImage{ID: "7", DiskID: "7", Visibility: "private"})
+	if err != nil {
+		t.Errorf("Error, %s", err)
+	}
+}
@@ -3,6 +3,7 @@ package hostingv4
 import (
 	"errors"
 	"strconv"
+	"time"
 
 	"github.com/PabloPie/go-gandi/hosting"
 )
@@ -10,22 +11,38 @@ import (
 // Private representation of an ip object in v4 of
 // Gandi's API, where IDs are integers instead of strings
 type iPAddressv4 struct {
-	ID       int    `xmlrpc:"id"`
-	IP       string `xmlrpc:"ip"`
-	RegionID int    `xmlrpc:"datacenter_id"`
-	Version  int    `xmlrpc:"version"`
-	VM       int    `xmlrpc:"vm_id"`
-	State    string `xmlrpc:"state"`
+	ID          int       `xmlrpc:"id"`
+	IP          string    `xmlrpc:"ip"`
+	RegionID    int       `xmlrpc:"datacenter_id"`
+	Version     int       `xmlrpc:"version"`
+	VM          int       `xmlrpc:"vm_id"`
+	State       string    `xmlrpc:"state"`
+	Reverse     string    `xmlrpc:"reverse"`
+	IfaceID     int       `xmlrpc:"iface_id"`
+	DateCreated time.Time `xmlrpc:"date_created"`
 }
 
 // Internally, ips are associated to interfaces, even though
 // we abstract those away, we need an internal object for
 // API responses
+//
+// See ifacev4.go for direct access to interfaces
 type iface struct {
-	IPs      []iPAddressv4 `xmlrpc:"ips"`
-	RegionID int           `xmlrpc:"datacenter_id"`
-	ID       int           `xmlrpc:"id"`
-	VMID     int           `xmlrpc:"vm_id"`
+	IPs       []iPAddressv4 `xmlrpc:"ips"`
+	RegionID  int           `xmlrpc:"datacenter_id"`
+	ID        int           `xmlrpc:"id"`
+	VMID      int           `xmlrpc:"vm_id"`
+	Bandwidth float32       `xmlrpc:"bandwidth"`
+	Type      string        `xmlrpc:"type"`
+	State     string        `xmlrpc:"state"`
+	Vlan      vlanRefv4     `xmlrpc:"vlan"`
+}
+
+// vlanRefv4 is the short description of a vlan
+// contained in an interface
+type vlanRefv4 struct {
+	ID   int    `xmlrpc:"id"`
+	Name string `xmlrpc:"name"`
 }
 
 // CreateIP creates an ip object that represents a public IP, either v4 or v6
@@ -33,11 +50,24 @@ type iface struct {
 // It requires a valid Region object, whose only mandatory field is its ID
 // An ipv6 is always created for the interface, even when only an ipv4 is requested
 func (h Hostingv4) CreateIP(region hosting.Region, version hosting.IPVersion) (hosting.IPAddress, error) {
+	return h.CreateIPFromSpec(hosting.IPSpec{
+		RegionID:  region.ID,
+		Version:   version,
+		Bandwidth: hosting.DefaultBandwidth,
+	})
+}
+
+// CreateIPFromSpec creates a public IP from `ipspec`
+//
+// If `ipspec.Bandwidth` is not set, the interface is created
+// with hosting.DefaultBandwidth
+func (h Hostingv4) CreateIPFromSpec(ipspec hosting.IPSpec) (hosting.IPAddress, error) {
+	version := ipspec.Version
 	if version != hosting.IPv4 && version != hosting.IPv6 {
 		return hosting.IPAddress{}, errors.New("Bad IP version")
 	}
 
-	regionID, err := strconv.Atoi(region.ID)
+	regionID, err := strconv.Atoi(ipspec.RegionID)
 	if err != nil {
 		return hosting.IPAddress{}, internalParseError("Region", "ID")
 	}
@@ -48,7 +78,7 @@ func (h Hostingv4) CreateIP(region hosting.Region, version hosting.IPVersion) (h
 		map[string]interface{}{
 			"datacenter_id": regionID,
 			"ip_version":    int(version),
-			"bandwidth":     hosting.DefaultBandwidth,
+			"bandwidth":     bandwidthOrDefault(ipspec.Bandwidth),
 		}}, &response)
 	if err != nil {
 		return hosting.IPAddress{}, err
@@ -65,6 +95,9 @@ func (h Hostingv4) CreateIP(region hosting.Region, version hosting.IPVersion) (h
 }
 
 // CreatePrivateIP creates a private IP within a specified vlan
+//
+// There is no spec to read a bandwidth from, so the interface
+// is created with hosting.DefaultBandwidth, as in CreateIP
 func (h Hostingv4) CreatePrivateIP(vlan hosting.Vlan, ip string) (hosting.IPAddress, error) {
 	var fn = "CreatePrivateIP"
 	if vlan.RegionID == "" || vlan.ID == "" {
@@ -99,10 +132,15 @@ func (h Hostingv4) CreatePrivateIP(vlan hosting.Vlan, ip string) (hosting.IPAddr
 		return hosting.IPAddress{}, err
 	}
 
-	return toIPAddress(ipv4), nil
+	privateip := toIPAddress(ipv4)
+	privateip.Vlan = vlan.ID
+	return privateip, nil
 }
 
 // ListIPs returns a list of ips filtered with the options provided in `diskFilter`
+//
+// With IPFilter.WithVlan, the interfaces of the IPs are requested
+// at once to know their vlan
 func (h Hostingv4) ListIPs(ipfilter hosting.IPFilter) ([]hosting.IPAddress, error) {
 	ipmap, err := ipFilterToMap(ipfilter)
 	if err != nil {
@@ -115,8 +153,23 @@ func (h Hostingv4) ListIPs(ipfilter hosting.IPFilter) ([]hosting.IPAddress, erro
 	}
 
 	var ips []hosting.IPAddress
+	var ifaceids []int
 	for _, iip := range response {
-		ips = append(ips, toIPAddress(iip))
+		if ip := toIPAddress(iip); ipfilter.Match(ip) {
+			ips = append(ips, ip)
+			ifaceids = append(ifaceids, iip.IfaceID)
+		}
+	}
+
+	if !ipfilter.WithVlan {
+		return ips, nil
+	}
+	vlans, err := h.vlansOfIfaces(ifaceids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range ips {
+		ips[i].Vlan = vlans[ifaceids[i]]
 	}
 
 	return ips, nil
@@ -137,14 +190,78 @@ func (h Hostingv4) DeleteIP(ip hosting.IPAddress) error {
 	if err != nil {
 		return err
 	}
-	err = h.Send("hosting.iface.delete", []interface{}{response.IfaceID}, &response)
+	return h.deleteIface(response.IfaceID)
+}
+
+// deleteIface deletes an interface and its IPs
+func (h Hostingv4) deleteIface(ifaceid int) error {
+	response := Operation{}
+	err := h.Send("hosting.iface.delete", []interface{}{ifaceid}, &response)
 	if err != nil {
 		return err
 	}
-
 	return h.waitForOp(response)
 }
 
+// UpdateIPBandwidth sets the bandwidth of `ip` to `bandwidth`, in kbps
+//
+// The bandwidth is set on the interface of the IP, so the other
+// IP of the interface (usually the ipv6) is also affected
+func (h Hostingv4) UpdateIPBandwidth(ip hosting.IPAddress, bandwidth float32) (hosting.IPAddress, error) {
+	var fn = "UpdateIPBandwidth"
+	if ip.ID == "" {
+		return hosting.IPAddress{}, &HostingError{fn, "IPAddress", "ID", ErrNotProvided}
+	}
+	if bandwidth <= 0 {
+		return hosting.IPAddress{}, &HostingError{fn, "-", "bandwidth", ErrNotProvided}
+	}
+	ipid, err := strconv.Atoi(ip.ID)
+	if err != nil {
+		return hosting.IPAddress{}, &HostingError{fn, "IPAddress", "ID", ErrParse}
+	}
+
+	ifaceid, err := h.ifaceIDFromIPID(ipid)
+	if err != nil {
+		return hosting.IPAddress{}, err
+	}
+	if err = h.updateIfaceBandwidth(ifaceid, bandwidth); err != nil {
+		return hosting.IPAddress{}, err
+	}
+
+	return h.ipFromID(ipid)
+}
+
+// SetReverseDNS sets the reverse of `ip` to `name`
+//
+// The function waits for the change to be applied before
+// returning the updated IP
+func (h Hostingv4) SetReverseDNS(ip hosting.IPAddress, name string) (hosting.IPAddress, error) {
+	var fn = "SetReverseDNS"
+	if ip.ID == "" {
+		return hosting.IPAddress{}, &HostingError{fn, "IPAddress", "ID", ErrNotProvided}
+	}
+	if name == "" {
+		return hosting.IPAddress{}, &HostingError{fn, "-", "name", ErrNotProvided}
+	}
+	ipid, err := strconv.Atoi(ip.ID)
+	if err != nil {
+		return hosting.IPAddress{}, &HostingError{fn, "IPAddress", "ID", ErrParse}
+	}
+
+	ipupdate := map[string]string{"reverse": name}
+	response := Operation{}
+	request := []interface{}{ipid, ipupdate}
+	err = h.Send("hosting.ip.update", request, &response)
+	if err != nil {
+		return hosting.IPAddress{}, err
+	}
+	if err = h.waitForOp(response); err != nil {
+		return hosting.IPAddress{}, err
+	}
+
+	return h.ipFromID(ipid)
+}
+
 // Get the interface associated to a specific IP
 func (h Hostingv4) ifaceIDFromIPID(ipid int) (int, error) {
 	// An operation already contains a field for iface_id
@@ -167,11 +284,54 @@ func (h Hostingv4) ipFromID(ipid int) (hosting.IPAddress, error) {
 	return toIPAddress(response), nil
 }
 
+// vlansOfIfaces returns the vlan ID of the interfaces `ifaceids`
+// that are in a vlan, only interfaces know the vlan of their IPs
+//
+// The interfaces are requested at once, unset IDs are ignored
+func (h Hostingv4) vlansOfIfaces(ifaceids []int) (map[int]string, error) {
+	vlans := map[int]string{}
+	var ids []int
+	seen := map[int]bool{}
+	for _, id := range ifaceids {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return vlans, nil
+	}
+
+	var filter interface{} = ids
+	if len(ids) == 1 {
+		filter = ids[0]
+	}
+	response := []iface{}
+	params := []interface{}{map[string]interface{}{"id": filter}}
+	if err := h.Send("hosting.iface.list", params, &response); err != nil {
+		return nil, err
+	}
+	for _, i := range response {
+		if i.Vlan.ID != 0 {
+			vlans[i.ID] = strconv.Itoa(i.Vlan.ID)
+		}
+	}
+	return vlans, nil
+}
+
+// bandwidthOrDefault returns hosting.DefaultBandwidth if
+// `bandwidth` was not set
+func bandwidthOrDefault(bandwidth float32) float32 {
+	if bandwidth <= 0 {
+		return hosting.DefaultBandwidth
+	}
+	return bandwidth
+}
+
 // Internal methods to convert Hosting structures to v4 structures
 
 func ipFilterToMap(ipfilter hosting.IPFilter) (map[string]interface{}, error) {
 	ipmap := make(map[string]interface{})
-	var err error
 
 	if ipfilter.Version != 0 {
 		if ipfilter.Version != hosting.IPv4 && ipfilter.Version != hosting.IPv6 {
@@ -180,26 +340,45 @@ func ipFilterToMap(ipfilter hosting.IPFilter) (map[string]interface{}, error) {
 		ipmap["version"] = int(ipfilter.Version)
 	}
 
-	if ipfilter.ID != "" {
-		ipmap["id"], err = strconv.Atoi(ipfilter.ID)
-		if err != nil {
-			return nil, internalParseError("hosting.IPFilter", "ID")
-		}
+	if id, ok := toIDFilter(ipfilter.ID, ipfilter.IDs); !ok {
+		return nil, internalParseError("hosting.IPFilter", "ID")
+	} else if id != nil {
+		ipmap["id"] = id
 	}
-	if ipfilter.RegionID != "" {
-		ipmap["datacenter_id"], err = strconv.Atoi(ipfilter.RegionID)
-		if err != nil {
-			return nil, internalParseError("hosting.IPFilter", "ID")
-		}
+	if region, ok := toIDFilter(ipfilter.RegionID, ipfilter.RegionIDs); !ok {
+		return nil, internalParseError("hosting.IPFilter", "RegionID")
+	} else if region != nil {
+		ipmap["datacenter_id"] = region
+	}
+	if state := toStringFilter("", ipfilter.States); state != nil {
+		ipmap["state"] = state
 	}
 
 	if ipfilter.IP != "" {
 		ipmap["ip"] = ipfilter.IP
 	}
 
+	if ipfilter.Reverse != "" {
+		ipmap["reverse"] = toNameFilter(ipfilter.Reverse)
+	}
+
 	return ipmap, nil
 }
 
+// ipsFromIface extracts the IPs of an interface, setting
+// their vlan as only interfaces know about it
+func ipsFromIface(i iface) []hosting.IPAddress {
+	var ips []hosting.IPAddress
+	for _, iip := range i.IPs {
+		ip := toIPAddress(iip)
+		if i.Vlan.ID != 0 {
+			ip.Vlan = strconv.Itoa(i.Vlan.ID)
+		}
+		ips = append(ips, ip)
+	}
+	return ips
+}
+
 // v4 API IP -> Hosting hosting.IPAddress
 func toIPAddress(iip iPAddressv4) (ip hosting.IPAddress) {
 	ip.ID = strconv.Itoa(iip.ID)
@@ -207,6 +386,8 @@ func toIPAddress(iip iPAddressv4) (ip hosting.IPAddress) {
 	ip.RegionID = strconv.Itoa(iip.RegionID)
 	ip.State = iip.State
 	ip.VM = strconv.Itoa(iip.VM)
+	ip.Reverse = iip.Reverse
+	ip.DateCreated = iip.DateCreated
 
 	if iip.Version == 6 {
 		ip.Version = hosting.IPv6
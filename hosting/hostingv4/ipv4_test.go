@@ -134,6 +134,7 @@ func TestCreatePrivateIP(t *testing.T) {
 		Version:  hosting.IPVersion(4),
 		VM:       "0",
 		State:    "created",
+		Vlan:     vlans[0].ID,
 	}
 
 	mockClient.EXPECT().Send("hosting.ip.info",
@@ -460,6 +461,79 @@ func TestListIPsByRegionIDAndVersion(t *testing.T) {
 	}
 }
 
+func TestListIPsWithVlan(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	public := iPAddressv4{ID: 100, IP: "92.243.17.196", RegionID: 123, Version: 4, State: "used", VM: 1, IfaceID: 10}
+	publicv6 := iPAddressv4{ID: 101, IP: "2001:4b98::1", RegionID: 123, Version: 6, State: "used", VM: 1, IfaceID: 10}
+	private := iPAddressv4{ID: 999, IP: "192.168.0.1", RegionID: 123, Version: 4, State: "used", VM: 1, IfaceID: 11}
+
+	list := mockClient.EXPECT().Send("hosting.ip.list",
+		[]interface{}{map[string]interface{}{}},
+		gomock.Any()).SetArg(2, []iPAddressv4{public, publicv6, private}).Return(nil)
+
+	mockClient.EXPECT().Send("hosting.iface.list",
+		[]interface{}{map[string]interface{}{"id": []int{10, 11}}},
+		gomock.Any()).SetArg(2, []iface{
+		{ID: 10, RegionID: 123, VMID: 1, Type: "public"},
+		{ID: 11, RegionID: 123, VMID: 1, Type: "private", Vlan: vlanRefv4{ID: 987, Name: "PrivateLAN1"}},
+	}).Return(nil).After(list)
+
+	ipsresult, err := testHosting.ListIPs(hosting.IPFilter{WithVlan: true})
+	if err != nil {
+		t.Fatalf("Error, %s", err)
+	}
+
+	expected := []hosting.IPAddress{toIPAddress(public), toIPAddress(publicv6), toIPAddress(private)}
+	expected[2].Vlan = "987"
+	if !reflect.DeepEqual(expected, ipsresult) {
+		t.Errorf("Error, expected %+v, got instead %+v", expected, ipsresult)
+	}
+}
+
+func TestListIPsWithoutVlan(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	// The interfaces are not requested
+	private := iPAddressv4{ID: 999, IP: "192.168.0.1", RegionID: 123, Version: 4, State: "used", VM: 1, IfaceID: 11}
+	mockClient.EXPECT().Send("hosting.ip.list",
+		[]interface{}{map[string]interface{}{}},
+		gomock.Any()).SetArg(2, []iPAddressv4{private}).Return(nil)
+
+	ipsresult, err := testHosting.ListIPs(hosting.IPFilter{})
+	expected := []hosting.IPAddress{toIPAddress(private)}
+	if err != nil || !reflect.DeepEqual(expected, ipsresult) {
+		t.Errorf("Error, expected %+v, got instead %+v (%v)", expected, ipsresult, err)
+	}
+}
+
+func TestListIPsWithVlanFailed(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	private := iPAddressv4{ID: 999, IP: "192.168.0.1", RegionID: 123, Version: 4, State: "free", IfaceID: 11}
+
+	list := mockClient.EXPECT().Send("hosting.ip.list",
+		[]interface{}{map[string]interface{}{}},
+		gomock.Any()).SetArg(2, []iPAddressv4{private}).Return(nil)
+
+	mockClient.EXPECT().Send("hosting.iface.list",
+		[]interface{}{map[string]interface{}{"id": 11}},
+		gomock.Any()).Return(errors.New("iface list failed")).After(list)
+
+	if _, err := testHosting.ListIPs(hosting.IPFilter{WithVlan: true}); err == nil {
+		t.Errorf("Error, expected error when listing the interfaces")
+	}
+}
+
 func TestDeleteIPBadID(t *testing.T) {
 	cl, _ := client.NewClientv4("", "1234")
 	testHosting := Newv4Hosting(cl)
@@ -511,3 +585,152 @@ func TestFilterBadRegionID(t *testing.T) {
 		t.Errorf("Error, expected error when parsing ID")
 	}
 }
+
+/* Reverse DNS */
+
+func TestSetReverseDNS(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	reverse := "mail.example.com"
+	ipupdated := ipsv4[0]
+	ipupdated.Reverse = reverse
+
+	update := mockClient.EXPECT().Send("hosting.ip.update",
+		[]interface{}{ipupdated.ID, map[string]string{"reverse": reverse}},
+		gomock.Any()).SetArg(2, Operation{ID: 42, IPID: ipupdated.ID}).Return(nil)
+
+	wait := mockClient.EXPECT().Send("operation.info",
+		[]interface{}{42},
+		gomock.Any()).SetArg(2, operationInfo{42, "DONE"}).Return(nil).After(update)
+
+	mockClient.EXPECT().Send("hosting.ip.info",
+		[]interface{}{ipupdated.ID},
+		gomock.Any()).SetArg(2, ipupdated).Return(nil).After(wait)
+
+	ipresult, err := testHosting.SetReverseDNS(toIPAddress(ipsv4[0]), reverse)
+	if err != nil {
+		t.Errorf("Error, %s", err)
+	}
+
+	expected := toIPAddress(ipupdated)
+	if !reflect.DeepEqual(expected, ipresult) {
+		t.Errorf("Error, expected %+v, got instead %+v", expected, ipresult)
+	}
+}
+
+func TestSetReverseDNSNoName(t *testing.T) {
+	cl, _ := client.NewClientv4("", "1234")
+	testHosting := Newv4Hosting(cl)
+
+	_, err := testHosting.SetReverseDNS(toIPAddress(ipsv4[0]), "")
+	if err == nil {
+		t.Errorf("Error, expected error when no reverse name is given")
+	}
+}
+
+func TestListIPsByReverse(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	ip := ipsv4[0]
+	ip.Reverse = "mail.example.com"
+	filter := hosting.IPFilter{Reverse: ip.Reverse}
+
+	mockClient.EXPECT().Send("hosting.ip.list",
+		[]interface{}{map[string]interface{}{"reverse": ip.Reverse}},
+		gomock.Any()).SetArg(2, []iPAddressv4{ip}).Return(nil)
+
+	ipsresult, _ := testHosting.ListIPs(filter)
+
+	expected := []hosting.IPAddress{toIPAddress(ip)}
+	if !reflect.DeepEqual(expected, ipsresult) {
+		t.Errorf("Error, expected %+v, got instead %+v", expected, ipsresult)
+	}
+}
+
+/* Bandwidth */
+
+func TestCreateIPFromSpecWithBandwidth(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	myOp := Operation{ID: 1, IPID: 100}
+	var bandwidth float32 = 204800
+
+	creation := mockClient.EXPECT().Send("hosting.iface.create",
+		[]interface{}{map[string]interface{}{
+			"datacenter_id": 123,
+			"ip_version":    4,
+			"bandwidth":     bandwidth,
+		}},
+		gomock.Any()).SetArg(2, myOp).Return(nil)
+
+	wait := mockClient.EXPECT().Send("operation.info",
+		[]interface{}{myOp.ID},
+		gomock.Any()).SetArg(2, operationInfo{myOp.ID, "DONE"}).Return(nil).After(creation)
+
+	mockClient.EXPECT().Send("hosting.ip.info",
+		[]interface{}{myOp.IPID},
+		gomock.Any()).SetArg(2, ipsv4[0]).Return(nil).After(wait)
+
+	ipspec := hosting.IPSpec{RegionID: "123", Version: hosting.IPv4, Bandwidth: bandwidth}
+	ipresult, _ := testHosting.CreateIPFromSpec(ipspec)
+
+	expected := toIPAddress(ipsv4[0])
+	if !reflect.DeepEqual(expected, ipresult) {
+		t.Errorf("Error, expected %+v, got instead %+v", expected, ipresult)
+	}
+}
+
+func TestUpdateIPBandwidth(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	ip := ipsv4[0]
+	var bandwidth float32 = 51200
+
+	info := mockClient.EXPECT().Send("hosting.ip.info",
+		[]interface{}{ip.ID},
+		gomock.Any()).SetArg(2, Operation{IfaceID: 10}).Return(nil)
+
+	update := mockClient.EXPECT().Send("hosting.iface.update",
+		[]interface{}{10, map[string]interface{}{"bandwidth": bandwidth}},
+		gomock.Any()).SetArg(2, Operation{ID: 7, IfaceID: 10}).Return(nil).After(info)
+
+	wait := mockClient.EXPECT().Send("operation.info",
+		[]interface{}{7},
+		gomock.Any()).SetArg(2, operationInfo{7, "DONE"}).Return(nil).After(update)
+
+	mockClient.EXPECT().Send("hosting.ip.info",
+		[]interface{}{ip.ID},
+		gomock.Any()).SetArg(2, ip).Return(nil).After(wait)
+
+	ipresult, err := testHosting.UpdateIPBandwidth(toIPAddress(ip), bandwidth)
+	if err != nil {
+		t.Errorf("Error, %s", err)
+	}
+
+	expected := toIPAddress(ip)
+	if !reflect.DeepEqual(expected, ipresult) {
+		t.Errorf("Error, expected %+v, got instead %+v", expected, ipresult)
+	}
+}
+
+func TestUpdateIPBandwidthNoBandwidth(t *testing.T) {
+	cl, _ := client.NewClientv4("", "1234")
+	testHosting := Newv4Hosting(cl)
+
+	_, err := testHosting.UpdateIPBandwidth(toIPAddress(ipsv4[0]), 0)
+	if err == nil {
+		t.Errorf("Error, expected error when no bandwidth is given")
+	}
+}
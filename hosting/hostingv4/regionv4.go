@@ -3,14 +3,20 @@ package hostingv4
 import (
 	"errors"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/PabloPie/go-gandi/hosting"
 )
 
 type regionv4 struct {
-	ID      int    `xmlrpc:"id"`
-	Name    string `xmlrpc:"dc_code"`
-	Country string `xmlrpc:"country"`
+	ID           int       `xmlrpc:"id"`
+	Name         string    `xmlrpc:"dc_code"`
+	Country      string    `xmlrpc:"country"`
+	City         string    `xmlrpc:"name"`
+	ISO          string    `xmlrpc:"iso"`
+	Status       string    `xmlrpc:"status"`
+	DeactivateAt time.Time `xmlrpc:"deactivate_at"`
 }
 
 // ListRegions lists every Gandi datacenter
@@ -29,6 +35,39 @@ func (h Hostingv4) ListRegions() ([]hosting.Region, error) {
 	return regions, nil
 }
 
+// FilterRegions returns the Gandi datacenters matching `regionfilter`
+//
+// The datacenter code and country are filtered by the API,
+// the status and deactivation date are checked locally
+func (h Hostingv4) FilterRegions(regionfilter hosting.RegionFilter) ([]hosting.Region, error) {
+	filter := map[string]string{}
+	if regionfilter.Name != "" {
+		filter["dc_code"] = regionfilter.Name
+	}
+	if regionfilter.ISO != "" {
+		filter["iso"] = strings.ToUpper(regionfilter.ISO)
+	}
+	request := []interface{}{}
+	if len(filter) > 0 {
+		request = append(request, filter)
+	}
+
+	response := []regionv4{}
+	err := h.Send("hosting.datacenter.list", request, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	var regions []hosting.Region
+	for _, r := range response {
+		region := fromRegionv4(r)
+		if regionfilter.Match(region) {
+			regions = append(regions, region)
+		}
+	}
+	return regions, nil
+}
+
 // RegionbyCode returns the region with code `code` if it exists
 func (h Hostingv4) RegionbyCode(code string) (hosting.Region, error) {
 	response := []regionv4{}
@@ -51,8 +90,28 @@ func (h Hostingv4) RegionbyCode(code string) (hosting.Region, error) {
 func fromRegionv4(region regionv4) hosting.Region {
 	id := strconv.Itoa(region.ID)
 	return hosting.Region{
-		ID:      id,
-		Name:    region.Name,
-		Country: region.Country,
+		ID:           id,
+		Name:         region.Name,
+		Country:      region.Country,
+		City:         region.City,
+		ISO:          region.ISO,
+		Status:       regionStatus(region, time.Now()),
+		DeactivateAt: region.DeactivateAt,
+	}
+}
+
+// regionStatus returns the status of a datacenter at time `now`,
+// the API only tells if it is open or closed, a datacenter still
+// open but with a deactivation date is closing
+func regionStatus(region regionv4, now time.Time) string {
+	if region.Status == hosting.RegionClosed {
+		return hosting.RegionClosed
+	}
+	if region.DeactivateAt.IsZero() {
+		return hosting.RegionOpen
+	}
+	if now.Before(region.DeactivateAt) {
+		return hosting.RegionClosing
 	}
+	return hosting.RegionClosed
 }
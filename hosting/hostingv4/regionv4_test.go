@@ -4,6 +4,7 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/PabloPie/go-gandi/hosting"
 	"github.com/PabloPie/go-gandi/mock"
@@ -80,3 +81,45 @@ func testRegionByCode(t *testing.T, code string) (hosting.Region, error) {
 
 	return testHosting.RegionbyCode(code)
 }
+
+func TestRegionStatus(t *testing.T) {
+	now := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		region   regionv4
+		expected string
+	}{
+		{regionv4{Status: "open"}, hosting.RegionOpen},
+		{regionv4{Status: "closed"}, hosting.RegionClosed},
+		{regionv4{Status: "open", DeactivateAt: now.AddDate(0, 3, 0)}, hosting.RegionClosing},
+		{regionv4{Status: "open", DeactivateAt: now.AddDate(0, -3, 0)}, hosting.RegionClosed},
+	}
+
+	for _, test := range tests {
+		if status := regionStatus(test.region, now); status != test.expected {
+			t.Errorf("Error, expected %s for %+v, got instead %s", test.expected, test.region, status)
+		}
+	}
+}
+
+func TestFilterRegionsOpenOnly(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	response := []regionv4{
+		{ID: 1, Name: "FR-SD2", ISO: "FR", City: "Paris", Status: "open", DeactivateAt: time.Now().AddDate(1, 0, 0)},
+		{ID: 2, Name: "FR-SD3", ISO: "FR", City: "Paris", Status: "open"},
+		{ID: 3, Name: "FR-SD1", ISO: "FR", City: "Paris", Status: "closed"},
+	}
+	mockClient.EXPECT().Send("hosting.datacenter.list",
+		[]interface{}{map[string]string{"iso": "FR"}},
+		gomock.Any()).SetArg(2, response).Return(nil)
+
+	regions, _ := testHosting.FilterRegions(hosting.RegionFilter{ISO: "fr", Status: hosting.RegionOpen})
+	expected := []hosting.Region{fromRegionv4(response[1])}
+
+	if !reflect.DeepEqual(expected, regions) {
+		t.Errorf("Error, expected %+v, got instead %+v", expected, regions)
+	}
+}
@@ -16,7 +16,18 @@ type sshkeyv4 struct {
 }
 
 // CreateKey creates a key from the given name and value
+//
+// The value is parsed locally so that an invalid key
+// is never sent
 func (h Hostingv4) CreateKey(name string, value string) (hosting.SSHKey, error) {
+	var fn = "CreateKey"
+	if name == "" {
+		return hosting.SSHKey{}, &HostingError{fn, "-", "name", ErrNotProvided}
+	}
+	if _, err := hosting.ParsePublicKey(value); err != nil {
+		return hosting.SSHKey{}, &HostingError{fn, "-", "value", err}
+	}
+
 	params := []interface{}{
 		map[string]string{
 			"name":  name,
@@ -28,7 +39,7 @@ func (h Hostingv4) CreateKey(name string, value string) (hosting.SSHKey, error)
 	if err != nil {
 		return hosting.SSHKey{}, err
 	}
-	return h.keyFromID(response.ID), nil
+	return h.keyFromID(response.ID)
 }
 
 // DeleteKey deletes an SSH Key
@@ -46,43 +57,75 @@ func (h Hostingv4) DeleteKey(key hosting.SSHKey) error {
 	return err
 }
 
-// KeyFromName returns the key with name `name`, or an empty
-// object if the key doesn't exist
-func (h Hostingv4) KeyFromName(name string) hosting.SSHKey {
+// KeyFromName returns the key with name `name`, or
+// hosting.ErrKeyNotFound if the key doesn't exist
+func (h Hostingv4) KeyFromName(name string) (hosting.SSHKey, error) {
 	params := []interface{}{
 		map[string]string{
 			"name": name,
 		}}
 	response := []sshkeyv4{}
 	err := h.Send("hosting.ssh.list", params, &response)
-	if err != nil || len(response) < 1 {
-		return hosting.SSHKey{}
+	if err != nil {
+		return hosting.SSHKey{}, err
+	}
+	if len(response) < 1 {
+		return hosting.SSHKey{}, hosting.ErrKeyNotFound
 	}
 	return h.keyFromID(response[0].ID)
 }
 
-// ListKeys lists every available key, without the corresponding values
-func (h Hostingv4) ListKeys() []hosting.SSHKey {
+// ListKeys lists every available key, with their values
+func (h Hostingv4) ListKeys() ([]hosting.SSHKey, error) {
 	response := []sshkeyv4{}
-	_ = h.Send("hosting.ssh.list", []interface{}{}, &response)
+	err := h.Send("hosting.ssh.list", []interface{}{}, &response)
+	if err != nil {
+		return nil, err
+	}
 
 	var keys = []hosting.SSHKey{}
 	for _, key := range response {
 		// Getting also the value of a key is optional...
-		fullkey := h.keyFromID(key.ID)
+		fullkey, err := h.keyFromID(key.ID)
+		if err != nil {
+			return nil, err
+		}
 		keys = append(keys, fullkey)
 	}
-	return keys
+	return keys, nil
 }
 
 // Helper functions
 
 // keyFromID is an internal function to get a general hosting.SSHKey from a v4 ID
-func (h Hostingv4) keyFromID(id int) hosting.SSHKey {
+func (h Hostingv4) keyFromID(id int) (hosting.SSHKey, error) {
 	params := []interface{}{id}
 	response := sshkeyv4{}
-	_ = h.Send("hosting.ssh.info", params, &response)
-	return toSSHKey(response)
+	err := h.Send("hosting.ssh.info", params, &response)
+	if err != nil {
+		return hosting.SSHKey{}, err
+	}
+	return toSSHKey(response), nil
+}
+
+// sshKeyID returns the v4 ID of `key`, looking it up
+// by name if its ID is not set
+func (h Hostingv4) sshKeyID(fn string, key hosting.SSHKey) (int, error) {
+	if key.ID == "" {
+		if key.Name == "" {
+			return 0, &HostingError{fn, "hosting.SSHKey", "ID", ErrNotProvided}
+		}
+		found, err := h.KeyFromName(key.Name)
+		if err != nil {
+			return 0, err
+		}
+		key = found
+	}
+	id, err := strconv.Atoi(key.ID)
+	if err != nil {
+		return 0, &HostingError{fn, "hosting.SSHKey", "ID", ErrParse}
+	}
+	return id, nil
 }
 
 // Conversion functions
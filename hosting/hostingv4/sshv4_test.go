@@ -1,6 +1,8 @@
 package hostingv4
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/PabloPie/go-gandi/hosting"
@@ -12,7 +14,7 @@ var (
 	keyid       = 1
 	keyidstr    = "1"
 	keyname     = "key1"
-	keyvalue    = "ssh-rsa 12345 test@hosting"
+	keyvalue    = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIIwU1b1hHgqAHzuJswCM0MjjoMLfWZPesjZ58T+e5XGB test@hosting"
 	fingerprint = "11:22:33:44"
 )
 
@@ -55,6 +57,20 @@ func TestCreateSSHKey(t *testing.T) {
 	}
 }
 
+func TestCreateSSHKeyInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	_, err := testHosting.CreateKey(keyname, "ssh-rsa 12345 test@hosting")
+	expected := &HostingError{"CreateKey", "-", "value", hosting.ErrBadKey}
+
+	if !reflect.DeepEqual(expected, err) {
+		t.Errorf("Error, expected %+v, got instead %+v", expected, err)
+	}
+}
+
 func TestDeleteSSHKey(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -119,7 +135,7 @@ func TestKeyFromName(t *testing.T) {
 		Fingerprint: fingerprint,
 		Value:       keyvalue,
 	}
-	key := testHosting.KeyFromName(keyname)
+	key, _ := testHosting.KeyFromName(keyname)
 
 	if key != expectedKey {
 		t.Errorf("Error, expected Key %+v, got instead %+v", expectedKey, key)
@@ -152,9 +168,52 @@ func TestListKeys(t *testing.T) {
 	mockClient.EXPECT().Send("hosting.ssh.info",
 		paramsInfoKey, gomock.Any()).SetArg(2, responseInfoKey).Return(nil).After(list)
 
-	keys := testHosting.ListKeys()
+	keys, _ := testHosting.ListKeys()
 
 	if len(keys) < 1 {
 		t.Errorf("Error, expected at least a key, got %d instead", len(keys))
 	}
 }
+
+func TestKeyFromNameNotFound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	paramsListKey := []interface{}{
+		map[string]string{
+			"name": keyname,
+		}}
+	mockClient.EXPECT().Send("hosting.ssh.list",
+		paramsListKey, gomock.Any()).SetArg(2, []sshkeyv4{}).Return(nil)
+
+	_, err := testHosting.KeyFromName(keyname)
+
+	if err != hosting.ErrKeyNotFound {
+		t.Errorf("Error, expected %v, got instead %v", hosting.ErrKeyNotFound, err)
+	}
+}
+
+func TestListKeysError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	apierror := errors.New("Invalid API key")
+	mockClient.EXPECT().Send("hosting.ssh.list",
+		[]interface{}{}, gomock.Any()).Return(apierror)
+
+	_, err := testHosting.ListKeys()
+	if err != apierror {
+		t.Errorf("Error, expected %v, got instead %v", apierror, err)
+	}
+
+	// The deprecated wrapper still hides the error
+	mockClient.EXPECT().Send("hosting.ssh.list",
+		[]interface{}{}, gomock.Any()).Return(apierror)
+	if keys := hosting.ListKeysOrEmpty(testHosting); len(keys) != 0 {
+		t.Errorf("Error, expected no keys, got instead %+v", keys)
+	}
+}
@@ -149,3 +149,44 @@ func TestRenameVlan(t *testing.T) {
 		t.Errorf("Error, expected %+v, got instead %+v", expectedVlan, vlan)
 	}
 }
+
+func TestListVlanMembers(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	vlanref := vlanRefv4{ID: 2, Name: "testvlan"}
+	paramsIfaceList := []interface{}{map[string]interface{}{"vlan": 2}}
+	responseIfaceList := []iface{
+		{ID: 10, RegionID: region, VMID: vmid, Type: "private", Vlan: vlanref,
+			IPs: []iPAddressv4{{ID: 100, IP: "192.168.0.2", RegionID: region, Version: 4, VM: vmid, State: "used"}}},
+		{ID: 11, RegionID: region, VMID: vmid, Type: "private", Vlan: vlanref,
+			IPs: []iPAddressv4{{ID: 101, IP: "192.168.0.3", RegionID: region, Version: 4, VM: vmid, State: "used"}}},
+		{ID: 12, RegionID: region, Type: "private", Vlan: vlanref,
+			IPs: []iPAddressv4{{ID: 102, IP: "192.168.0.4", RegionID: region, Version: 4, State: "free"}}},
+	}
+	list := mockClient.EXPECT().Send("hosting.iface.list",
+		paramsIfaceList, gomock.Any()).SetArg(2, responseIfaceList).Return(nil)
+
+	responseVMInfo := vmv4{ID: vmid, Hostname: vmname, RegionID: region, Ifaces: responseIfaceList[:2]}
+	mockClient.EXPECT().Send("hosting.vm.info",
+		[]interface{}{vmid}, gomock.Any()).SetArg(2, responseVMInfo).Return(nil).After(list)
+
+	members, err := testHosting.ListVlanMembers(hosting.Vlan{ID: "2"})
+	if err != nil {
+		t.Errorf("Error, %s", err)
+	}
+
+	if len(members.VMs) != 1 || members.VMs[0].ID != vmidstr {
+		t.Errorf("Error, expected VM %s as only member, got instead %+v", vmidstr, members.VMs)
+	}
+	if len(members.IPs) != 3 {
+		t.Errorf("Error, expected 3 IPs, got instead %+v", members.IPs)
+	}
+	for _, ip := range members.IPs {
+		if ip.Vlan != "2" {
+			t.Errorf("Error, expected IP %s to be in vlan 2, got instead '%s'", ip.IP, ip.Vlan)
+		}
+	}
+}
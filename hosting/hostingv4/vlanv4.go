@@ -72,7 +72,7 @@ func (h Hostingv4) VlanFromName(name string) (hosting.Vlan, error) {
 		return hosting.Vlan{}, err
 	}
 	if len(vlans) < 1 {
-		return hosting.Vlan{}, fmt.Errorf("Vlan '%s' does not exist", name)
+		return hosting.Vlan{}, hosting.ErrVlanNotFound
 	}
 
 	return vlans[0], nil
@@ -97,7 +97,9 @@ func (h Hostingv4) ListVlans(vlanfilter hosting.VlanFilter) ([]hosting.Vlan, err
 
 	var vlans []hosting.Vlan
 	for _, vlan := range response {
-		vlans = append(vlans, fromVlanv4(vlan))
+		if res := fromVlanv4(vlan); vlanfilter.Match(res) {
+			vlans = append(vlans, res)
+		}
 	}
 
 	return vlans, nil
@@ -190,6 +192,43 @@ func (h Hostingv4) DeleteVlan(vlan hosting.Vlan) error {
 	return err
 }
 
+// ListVlanMembers returns the VMs and private IPs connected to `vlan`
+//
+// Every VM with at least one interface in the vlan is returned once
+func (h Hostingv4) ListVlanMembers(vlan hosting.Vlan) (hosting.VlanMembers, error) {
+	var fn = "ListVlanMembers"
+	if vlan.ID == "" {
+		return hosting.VlanMembers{}, &HostingError{fn, "Vlan", "ID", ErrNotProvided}
+	}
+	vlanid, err := strconv.Atoi(vlan.ID)
+	if err != nil {
+		return hosting.VlanMembers{}, &HostingError{fn, "Vlan", "ID", ErrParse}
+	}
+
+	response := []iface{}
+	params := []interface{}{map[string]interface{}{"vlan": vlanid}}
+	err = h.Send("hosting.iface.list", params, &response)
+	if err != nil {
+		return hosting.VlanMembers{}, err
+	}
+
+	members := hosting.VlanMembers{}
+	seen := map[int]bool{}
+	for _, i := range response {
+		members.IPs = append(members.IPs, ipsFromIface(i)...)
+		if i.VMID == 0 || seen[i.VMID] {
+			continue
+		}
+		seen[i.VMID] = true
+		vm, err := h.vmFromID(i.VMID)
+		if err != nil {
+			return hosting.VlanMembers{}, err
+		}
+		members.VMs = append(members.VMs, vm)
+	}
+	return members, nil
+}
+
 // Conversion functions
 
 // Hosting VlanSpec -> v4 VlanSpec
@@ -229,7 +268,7 @@ func toVlanFilterv4(vlan hosting.VlanFilter) (vlanFilterv4, error) {
 	return vlanFilterv4{
 		RegionID: regions,
 		ID:       ids,
-		Name:     vlan.Name,
+		Name:     toNameFilter(vlan.Name),
 	}, nil
 }
 
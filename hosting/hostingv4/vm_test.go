@@ -1,6 +1,7 @@
 package hostingv4
 
 import (
+	"errors"
 	"log"
 	"reflect"
 	"strconv"
@@ -61,10 +62,11 @@ func TestCreateVM(t *testing.T) {
 		paramsWait, gomock.Any()).SetArg(2, responseWait).Return(nil).After(creation)
 
 	paramsVMInfo := []interface{}{responseVMCreate[2].VMID}
-	ipsresponse := []iPAddressv4{{1, "192.168.1.1", region, 4, vmid, "used"}}
-	ifaceresponse := []iface{{ipsresponse, region, 1, vmid}}
-	diskresponse := []diskv4{{1, "sysdisk_1", disksizeMB, region, "created", "data", []int{vmid}, true}}
-	responseVMInfo := vmv4{vmid, vmname, region, "", "", 1, 512, now, ifaceresponse, diskresponse, "running"}
+	ipsresponse := []iPAddressv4{{ID: 1, IP: "192.168.1.1", RegionID: region, Version: 4, VM: vmid, State: "used"}}
+	ifaceresponse := []iface{{IPs: ipsresponse, RegionID: region, ID: 1, VMID: vmid}}
+	diskresponse := []diskv4{{ID: 1, Name: "sysdisk_1", Size: disksizeMB, RegionID: region, State: "created", Type: "data", VM: []int{vmid}, BootDisk: true}}
+	responseVMInfo := vmv4{ID: vmid, Hostname: vmname, RegionID: region, Cores: 1, Memory: 512,
+		DateCreated: now, Ifaces: ifaceresponse, Disks: diskresponse, State: "running"}
 	mockClient.EXPECT().Send("hosting.vm.info",
 		paramsVMInfo, gomock.Any()).SetArg(2, responseVMInfo).Return(nil).After(wait)
 
@@ -106,6 +108,161 @@ func TestCreateVM(t *testing.T) {
 		t.Errorf("Error, expected %+v, got instead %+v", expected, vm)
 	}
 }
+
+// The created VM is read from the VM ID of the operation,
+// which is not the ID of the operation itself
+func TestCreateVMReadsVMIDOfOperation(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	responseVMCreate := []Operation{{}, {}, {ID: 42, VMID: vmid}}
+	creation := mockClient.EXPECT().Send("hosting.vm.create_from",
+		gomock.Any(), gomock.Any()).SetArg(2, responseVMCreate).Return(nil)
+	wait := mockClient.EXPECT().Send("operation.info",
+		[]interface{}{42}, gomock.Any()).SetArg(2, operationInfo{42, "DONE"}).Return(nil).After(creation)
+
+	ifaceresponse := []iface{{IPs: []iPAddressv4{{ID: 1, IP: "192.168.1.1", RegionID: region, Version: 4, VM: vmid}},
+		RegionID: region, ID: 1, VMID: vmid}}
+	diskresponse := []diskv4{{ID: diskid, Name: "sysdisk_1", RegionID: region, VM: []int{vmid}, BootDisk: true}}
+	mockClient.EXPECT().Send("hosting.vm.info",
+		[]interface{}{vmid}, gomock.Any()).SetArg(2, vmv4{ID: vmid, Hostname: vmname, RegionID: region,
+		Ifaces: ifaceresponse, Disks: diskresponse}).Return(nil).After(wait)
+
+	vmspec := hosting.VMSpec{RegionID: regionstr, Hostname: vmname}
+	diskimage := hosting.DiskImage{DiskID: imageidstr, RegionID: regionstr}
+	vm, _, _, err := testHosting.CreateVM(vmspec, diskimage, hosting.IPv4, 20)
+	if err != nil || vm.ID != vmidstr {
+		t.Errorf("Error, expected VM %s, got instead %+v (%v)", vmidstr, vm, err)
+	}
+}
+
+func TestCreateVMRollback(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	paramsVMCreate := []interface{}{
+		map[string]interface{}{
+			"ip_version":    4,
+			"bandwidth":     hosting.DefaultBandwidth,
+			"datacenter_id": region,
+			"hostname":      vmname,
+		},
+		map[string]interface{}{
+			"datacenter_id": region,
+			"size":          disksizeMB,
+		}, imageid}
+	responseVMCreate := []Operation{{ID: 1, IfaceID: 3}, {ID: 2, DiskID: diskid}, {ID: 3, VMID: vmid}}
+	creation := mockClient.EXPECT().Send("hosting.vm.create_from",
+		paramsVMCreate, gomock.Any()).SetArg(2, responseVMCreate).Return(nil)
+
+	wait := mockClient.EXPECT().Send("operation.info",
+		[]interface{}{3}, gomock.Any()).Return(errors.New("timeout")).After(creation)
+
+	// The VM exists, it is stopped and deleted with its disk and interface
+	responseVMInfo := vmv4{ID: vmid, Hostname: vmname, RegionID: region, State: "running"}
+	info := mockClient.EXPECT().Send("hosting.vm.info",
+		[]interface{}{vmid}, gomock.Any()).SetArg(2, responseVMInfo).Return(nil).After(wait)
+
+	stop := mockClient.EXPECT().Send("hosting.vm.stop",
+		[]interface{}{vmid}, gomock.Any()).SetArg(2, Operation{ID: 4}).Return(nil).After(info)
+	waitstop := mockClient.EXPECT().Send("operation.info",
+		[]interface{}{4}, gomock.Any()).SetArg(2, operationInfo{4, "DONE"}).Return(nil).After(stop)
+	del := mockClient.EXPECT().Send("hosting.vm.delete",
+		[]interface{}{vmid}, gomock.Any()).SetArg(2, Operation{ID: 5}).Return(nil).After(waitstop)
+	mockClient.EXPECT().Send("operation.info",
+		[]interface{}{5}, gomock.Any()).SetArg(2, operationInfo{5, "DONE"}).Return(nil).After(del)
+
+	vmspec := hosting.VMSpec{RegionID: regionstr, Hostname: vmname, Rollback: true}
+	diskimage := hosting.DiskImage{DiskID: imageidstr, RegionID: regionstr}
+	_, _, _, err := testHosting.CreateVM(vmspec, diskimage, hosting.IPv4, 20)
+
+	rberr, ok := err.(*hosting.RollbackError)
+	if !ok {
+		t.Fatalf("Error, expected a RollbackError, got instead %v", err)
+	}
+	expected := []string{"VM 1", "Disk 1", "interface 3"}
+	if !reflect.DeepEqual(expected, rberr.Deleted) || len(rberr.Left) != 0 {
+		t.Errorf("Error, expected %v deleted, got instead %+v", expected, rberr)
+	}
+}
+
+func TestCreateVMRollbackWithoutVM(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	responseVMCreate := []Operation{{ID: 1, IfaceID: 3}, {ID: 2, DiskID: diskid}, {ID: 3, VMID: vmid}}
+	creation := mockClient.EXPECT().Send("hosting.vm.create_from",
+		gomock.Any(), gomock.Any()).SetArg(2, responseVMCreate).Return(nil)
+	wait := mockClient.EXPECT().Send("operation.info",
+		[]interface{}{3}, gomock.Any()).Return(errors.New("timeout")).After(creation)
+	info := mockClient.EXPECT().Send("hosting.vm.info",
+		[]interface{}{vmid}, gomock.Any()).Return(errors.New("unavailable")).After(wait)
+
+	// The VM may exist, nothing can be deleted
+	mockClient.EXPECT().Send("hosting.vm.list",
+		[]interface{}{map[string]interface{}{"id": vmid}}, gomock.Any()).Return(errors.New("unavailable")).After(info)
+
+	vmspec := hosting.VMSpec{RegionID: regionstr, Hostname: vmname, Rollback: true}
+	diskimage := hosting.DiskImage{DiskID: imageidstr, RegionID: regionstr}
+	_, _, _, err := testHosting.CreateVM(vmspec, diskimage, hosting.IPv4, 20)
+
+	rberr, ok := err.(*hosting.RollbackError)
+	if !ok {
+		t.Fatalf("Error, expected a RollbackError, got instead %v", err)
+	}
+	expected := []string{"VM 1", "Disk 1", "interface 3"}
+	if len(rberr.Deleted) > 0 || !reflect.DeepEqual(expected, rberr.Left) {
+		t.Errorf("Error, expected %v left, got instead %+v", expected, rberr)
+	}
+}
+
+func TestCreateVMRollbackVMNotCreated(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	responseVMCreate := []Operation{{ID: 1, IfaceID: 3}, {ID: 2, DiskID: diskid}, {ID: 3, VMID: vmid}}
+	creation := mockClient.EXPECT().Send("hosting.vm.create_from",
+		gomock.Any(), gomock.Any()).SetArg(2, responseVMCreate).Return(nil)
+	wait := mockClient.EXPECT().Send("operation.info",
+		[]interface{}{3}, gomock.Any()).Return(errors.New("timeout")).After(creation)
+	info := mockClient.EXPECT().Send("hosting.vm.info",
+		[]interface{}{vmid}, gomock.Any()).Return(errors.New("not found")).After(wait)
+	list := mockClient.EXPECT().Send("hosting.vm.list",
+		[]interface{}{map[string]interface{}{"id": vmid}}, gomock.Any()).SetArg(2, []vmv4{}).Return(nil).After(info)
+
+	// The disk is deleted, the interface cannot be
+	deldisk := mockClient.EXPECT().Send("hosting.disk.delete",
+		[]interface{}{diskid}, gomock.Any()).SetArg(2, Operation{ID: 4}).Return(nil).After(list)
+	waitdisk := mockClient.EXPECT().Send("operation.info",
+		[]interface{}{4}, gomock.Any()).SetArg(2, operationInfo{4, "DONE"}).Return(nil).After(deldisk)
+	mockClient.EXPECT().Send("hosting.iface.delete",
+		[]interface{}{3}, gomock.Any()).Return(errors.New("locked")).After(waitdisk)
+
+	vmspec := hosting.VMSpec{RegionID: regionstr, Hostname: vmname, Rollback: true}
+	diskimage := hosting.DiskImage{DiskID: imageidstr, RegionID: regionstr}
+	_, _, _, err := testHosting.CreateVM(vmspec, diskimage, hosting.IPv4, 20)
+
+	rberr, ok := err.(*hosting.RollbackError)
+	if !ok {
+		t.Fatalf("Error, expected a RollbackError, got instead %v", err)
+	}
+	if !reflect.DeepEqual([]string{"Disk 1"}, rberr.Deleted) || !reflect.DeepEqual([]string{"interface 3"}, rberr.Left) {
+		t.Errorf("Error, unexpected rollback %+v", rberr)
+	}
+	expected := "timeout, deleted Disk 1, could not delete interface 3"
+	if err.Error() != expected {
+		t.Errorf("Error, expected '%s', got instead '%s'", expected, err.Error())
+	}
+}
+
 func TestCreateVMWithExistingIP(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -139,10 +296,11 @@ func TestCreateVMWithExistingIP(t *testing.T) {
 		paramsWait, gomock.Any()).SetArg(2, responseWait).Return(nil).After(creation)
 
 	paramsVMInfo := []interface{}{responseVMCreate[2].VMID}
-	ipsresponse := []iPAddressv4{{1, "192.168.1.1", region, 4, vmid, "used"}}
-	ifaceresponse := []iface{{ipsresponse, region, 10, vmid}}
-	diskresponse := []diskv4{{1, "sysdisk_1", disksizeMB, region, "created", "data", []int{vmid}, true}}
-	responseVMInfo := vmv4{vmid, vmname, region, "", "", 1, 512, now, ifaceresponse, diskresponse, "running"}
+	ipsresponse := []iPAddressv4{{ID: 1, IP: "192.168.1.1", RegionID: region, Version: 4, VM: vmid, State: "used"}}
+	ifaceresponse := []iface{{IPs: ipsresponse, RegionID: region, ID: 10, VMID: vmid}}
+	diskresponse := []diskv4{{ID: 1, Name: "sysdisk_1", Size: disksizeMB, RegionID: region, State: "created", Type: "data", VM: []int{vmid}, BootDisk: true}}
+	responseVMInfo := vmv4{ID: vmid, Hostname: vmname, RegionID: region, Cores: 1, Memory: 512,
+		DateCreated: now, Ifaces: ifaceresponse, Disks: diskresponse, State: "running"}
 	mockClient.EXPECT().Send("hosting.vm.info",
 		paramsVMInfo, gomock.Any()).SetArg(2, responseVMInfo).Return(nil).After(wait)
 
@@ -221,10 +379,11 @@ func TestCreateVMWithExistingDiskAndIP(t *testing.T) {
 		paramsWait, gomock.Any()).SetArg(2, responseWait).Return(nil).After(creation)
 
 	paramsVMInfo := []interface{}{responseVMCreate[1].VMID}
-	ipsresponse := []iPAddressv4{{1, "192.168.1.1", region, 4, vmid, "used"}}
-	ifaceresponse := []iface{{ipsresponse, region, 10, vmid}}
-	diskresponse := []diskv4{{1, "sysdisk_1", disksizeMB, region, "created", "data", []int{vmid}, true}}
-	responseVMInfo := vmv4{vmid, vmname, region, "", "", 1, 512, now, ifaceresponse, diskresponse, "running"}
+	ipsresponse := []iPAddressv4{{ID: 1, IP: "192.168.1.1", RegionID: region, Version: 4, VM: vmid, State: "used"}}
+	ifaceresponse := []iface{{IPs: ipsresponse, RegionID: region, ID: 10, VMID: vmid}}
+	diskresponse := []diskv4{{ID: 1, Name: "sysdisk_1", Size: disksizeMB, RegionID: region, State: "created", Type: "data", VM: []int{vmid}, BootDisk: true}}
+	responseVMInfo := vmv4{ID: vmid, Hostname: vmname, RegionID: region, Cores: 1, Memory: 512,
+		DateCreated: now, Ifaces: ifaceresponse, Disks: diskresponse, State: "running"}
 	mockClient.EXPECT().Send("hosting.vm.info",
 		paramsVMInfo, gomock.Any()).SetArg(2, responseVMInfo).Return(nil).After(wait)
 
@@ -290,27 +449,28 @@ func TestDiskDetach(t *testing.T) {
 		paramsWait, gomock.Any()).SetArg(2, responseWait).Return(nil).After(attach)
 
 	paramsVMInfo := []interface{}{responseDiskDetach.VMID}
-	ipsresponse := []iPAddressv4{{2, "192.168.1.1", region, 4, 3, "used"}}
-	ifaceresponse := []iface{{ipsresponse, region, 2, 3}}
-	diskresponse := []diskv4{{1, "sysdisk_1", disksizeMB, region, "created", "data", []int{3}, true}}
-	responseVMInfo := vmv4{3, vmname, region, "", "", 1, 512, now, ifaceresponse, diskresponse, "running"}
+	ipsresponse := []iPAddressv4{{ID: 2, IP: "192.168.1.1", RegionID: region, Version: 4, VM: 3, State: "used"}}
+	ifaceresponse := []iface{{IPs: ipsresponse, RegionID: region, ID: 2, VMID: 3}}
+	diskresponse := []diskv4{{ID: 1, Name: "sysdisk_1", Size: disksizeMB, RegionID: region, State: "created", Type: "data", VM: []int{3}, BootDisk: true}}
+	responseVMInfo := vmv4{ID: 3, Hostname: vmname, RegionID: region, Cores: 1, Memory: 512,
+		DateCreated: now, Ifaces: ifaceresponse, Disks: diskresponse, State: "running"}
 	info := mockClient.EXPECT().Send("hosting.vm.info",
 		paramsVMInfo, gomock.Any()).SetArg(2, responseVMInfo).Return(nil).After(wait)
 
 	paramsDiskInfo := []interface{}{4}
-	responseDiskInfo := diskv4{4, "disk2", 10240, region, "created", "data", []int{}, false}
+	responseDiskInfo := diskv4{ID: 4, Name: "disk2", Size: 10240, RegionID: region, State: "created", Type: "data", VM: []int{}, BootDisk: false}
 	mockClient.EXPECT().Send("hosting.disk.info",
 		paramsDiskInfo, gomock.Any()).SetArg(2, responseDiskInfo).Return(nil).After(info)
 
 	disks := []hosting.Disk{
-		{"4", "disk2", 10, regionstr, "created", "data", []string{"3"}, false},
-		{"1", "sysdisk_1", disksize, regionstr, "created", "data", []string{"3"}, true},
+		{ID: "4", Name: "disk2", Size: 10, RegionID: regionstr, State: "created", Type: "data", VM: []string{"3"}, BootDisk: false},
+		{ID: "1", Name: "sysdisk_1", Size: disksize, RegionID: regionstr, State: "created", Type: "data", VM: []string{"3"}, BootDisk: true},
 	}
 	vm := hosting.VM{ID: "3", Disks: disks}
 	disk := hosting.Disk{ID: "4"}
 	vmres, _, _ := testHosting.DetachDisk(vm, disk)
 
-	expectedDisks := []hosting.Disk{{"4", "disk2", 10, regionstr, "created", "data", []string{"3"}, false}}
+	expectedDisks := []hosting.Disk{{ID: "4", Name: "disk2", Size: 10, RegionID: regionstr, State: "created", Type: "data", VM: []string{"3"}, BootDisk: false}}
 	expected := hosting.VM{
 		ID:    "3",
 		Disks: expectedDisks,
@@ -331,9 +491,9 @@ func TestDiskAttachAtPosition(t *testing.T) {
 	vmidStr := strconv.Itoa(vmid)
 
 	disks := []hosting.Disk{
-		{"1", "d1", disksize, regionstr, "created", "data", []string{vmidStr}, true},
-		{"2", "d2", disksize, regionstr, "created", "data", []string{vmidStr}, true},
-		{"3", "d3", disksize, regionstr, "created", "data", []string{vmidStr}, true},
+		{ID: "1", Name: "d1", Size: disksize, RegionID: regionstr, State: "created", Type: "data", VM: []string{vmidStr}, BootDisk: true},
+		{ID: "2", Name: "d2", Size: disksize, RegionID: regionstr, State: "created", Type: "data", VM: []string{vmidStr}, BootDisk: true},
+		{ID: "3", Name: "d3", Size: disksize, RegionID: regionstr, State: "created", Type: "data", VM: []string{vmidStr}, BootDisk: true},
 	}
 	diskid := 3
 	diskidStr := strconv.Itoa(diskid)
@@ -358,9 +518,9 @@ func TestDiskAttachAtPosition(t *testing.T) {
 
 	paramsVMInfo := []interface{}{response.VMID}
 
-	diskresponse := []diskv4{{3, "d3", disksizeMB, region, "created", "data", []int{vmid}, true},
-		{2, "d2", disksizeMB, region, "created", "data", []int{vmid}, true},
-		{1, "d1", disksizeMB, region, "created", "data", []int{vmid}, true},
+	diskresponse := []diskv4{{ID: 3, Name: "d3", Size: disksizeMB, RegionID: region, State: "created", Type: "data", VM: []int{vmid}, BootDisk: true},
+		{ID: 2, Name: "d2", Size: disksizeMB, RegionID: region, State: "created", Type: "data", VM: []int{vmid}, BootDisk: true},
+		{ID: 1, Name: "d1", Size: disksizeMB, RegionID: region, State: "created", Type: "data", VM: []int{vmid}, BootDisk: true},
 	}
 
 	responseVMInfo := vmv4{Disks: diskresponse}
@@ -403,27 +563,31 @@ func TestIPDetach(t *testing.T) {
 		paramsWait, gomock.Any()).SetArg(2, responseWait).Return(nil).After(attach)
 
 	paramsVMInfo := []interface{}{responseIPDetach.VMID}
-	ipsresponse := []iPAddressv4{{2, "192.168.1.1", region, 4, 3, "used"}}
-	ifaceresponse := []iface{{ipsresponse, region, 2, vmid}}
-	diskresponse := []diskv4{{1, "sysdisk_1", disksizeMB, region, "created", "data", []int{vmid}, true}}
-	responseVMInfo := vmv4{vmid, vmname, region, "", "", 1, 512, now, ifaceresponse, diskresponse, "running"}
+	ipsresponse := []iPAddressv4{{ID: 2, IP: "192.168.1.1", RegionID: region, Version: 4, VM: 3, State: "used"}}
+	ifaceresponse := []iface{{IPs: ipsresponse, RegionID: region, ID: 2, VMID: vmid}}
+	diskresponse := []diskv4{{ID: 1, Name: "sysdisk_1", Size: disksizeMB, RegionID: region, State: "created", Type: "data", VM: []int{vmid}, BootDisk: true}}
+	responseVMInfo := vmv4{ID: vmid, Hostname: vmname, RegionID: region, Cores: 1, Memory: 512,
+		DateCreated: now, Ifaces: ifaceresponse, Disks: diskresponse, State: "running"}
 	info := mockClient.EXPECT().Send("hosting.vm.info",
 		paramsVMInfo, gomock.Any()).SetArg(2, responseVMInfo).Return(nil).After(wait)
 
 	paramsIPInfo2 := []interface{}{3}
-	responseIPInfo2 := iPAddressv4{3, "192.168.10.2", region, 4, 0, "created"}
+	responseIPInfo2 := iPAddressv4{ID: 3, IP: "192.168.10.2", RegionID: region, Version: 4, VM: 0, State: "created"}
 	mockClient.EXPECT().Send("hosting.ip.info",
 		paramsIPInfo2, gomock.Any()).SetArg(2, responseIPInfo2).Return(nil).After(info)
 
 	ips := []hosting.IPAddress{
-		{"2", "192.168.1.1", regionstr, hosting.IPVersion(4), "3", "used"},
-		{"3", "192.168.10.2", regionstr, hosting.IPVersion(4), "3", "used"},
+		{ID: "2", IP: "192.168.1.1", RegionID: regionstr,
+			Version: hosting.IPVersion(4), VM: "3", State: "used"},
+		{ID: "3", IP: "192.168.10.2", RegionID: regionstr,
+			Version: hosting.IPVersion(4), VM: "3", State: "used"},
 	}
 	vm := hosting.VM{ID: "3", Ips: ips}
 	ip := hosting.IPAddress{ID: "3"}
 	vmres, _, _ := testHosting.DetachIP(vm, ip)
 
-	expectedIPS := []hosting.IPAddress{{"2", "192.168.1.1", regionstr, hosting.IPVersion(4), "3", "used"}}
+	expectedIPS := []hosting.IPAddress{{ID: "2", IP: "192.168.1.1", RegionID: regionstr,
+		Version: hosting.IPVersion(4), VM: "3", State: "used"}}
 	expected := hosting.VM{
 		ID:  "3",
 		Ips: expectedIPS,
@@ -483,10 +647,11 @@ func TestCreateVMWithExistingDisk(t *testing.T) {
 		paramsWait, gomock.Any()).SetArg(2, responseWait).Return(nil).After(creation)
 
 	paramsVMInfo := []interface{}{responseVMCreate[1].VMID}
-	ipsresponse := []iPAddressv4{{1, "192.168.1.1", region, 4, vmid, "used"}}
-	ifaceresponse := []iface{{ipsresponse, region, 1, vmid}}
-	diskresponse := []diskv4{{5, "sysdisk_1", disksizeMB, region, "created", "data", []int{vmid}, true}}
-	responseVMInfo := vmv4{vmid, vmname, region, "", "", 1, 512, now, ifaceresponse, diskresponse, "running"}
+	ipsresponse := []iPAddressv4{{ID: 1, IP: "192.168.1.1", RegionID: region, Version: 4, VM: vmid, State: "used"}}
+	ifaceresponse := []iface{{IPs: ipsresponse, RegionID: region, ID: 1, VMID: vmid}}
+	diskresponse := []diskv4{{ID: 5, Name: "sysdisk_1", Size: disksizeMB, RegionID: region, State: "created", Type: "data", VM: []int{vmid}, BootDisk: true}}
+	responseVMInfo := vmv4{ID: vmid, Hostname: vmname, RegionID: region, Cores: 1, Memory: 512,
+		DateCreated: now, Ifaces: ifaceresponse, Disks: diskresponse, State: "running"}
 	mockClient.EXPECT().Send("hosting.vm.info",
 		paramsVMInfo, gomock.Any()).SetArg(2, responseVMInfo).Return(nil).After(wait)
 
@@ -510,8 +675,9 @@ func TestCreateVMWithExistingDisk(t *testing.T) {
 		log.Println(err)
 	}
 
-	expectedIPS := []hosting.IPAddress{{"1", "192.168.1.1", regionstr, hosting.IPVersion(4), vmidstr, "used"}}
-	expectedDisks := []hosting.Disk{{"5", "sysdisk_1", disksize, regionstr, "created", "data", []string{vmidstr}, true}}
+	expectedIPS := []hosting.IPAddress{{ID: "1", IP: "192.168.1.1", RegionID: regionstr,
+		Version: hosting.IPVersion(4), VM: vmidstr, State: "used"}}
+	expectedDisks := []hosting.Disk{{ID: "5", Name: "sysdisk_1", Size: disksize, RegionID: regionstr, State: "created", Type: "data", VM: []string{vmidstr}, BootDisk: true}}
 	expected := hosting.VM{
 		ID:          vmidstr,
 		Hostname:    vmname,
@@ -545,17 +711,19 @@ func TestVMFromName(t *testing.T) {
 		paramsVMList, gomock.Any()).SetArg(2, responseVMList).Return(nil)
 
 	paramsVMInfo := []interface{}{responseVMList[0].ID}
-	ipsresponse := []iPAddressv4{{1, "192.168.1.1", region, 4, vmid, "used"}}
-	ifaceresponse := []iface{{ipsresponse, region, 1, vmid}}
-	diskresponse := []diskv4{{5, "sysdisk_1", disksizeMB, region, "created", "data", []int{vmid}, true}}
-	responseVMInfo := vmv4{vmid, vmname, region, "", "", 1, 512, now, ifaceresponse, diskresponse, "running"}
+	ipsresponse := []iPAddressv4{{ID: 1, IP: "192.168.1.1", RegionID: region, Version: 4, VM: vmid, State: "used"}}
+	ifaceresponse := []iface{{IPs: ipsresponse, RegionID: region, ID: 1, VMID: vmid}}
+	diskresponse := []diskv4{{ID: 5, Name: "sysdisk_1", Size: disksizeMB, RegionID: region, State: "created", Type: "data", VM: []int{vmid}, BootDisk: true}}
+	responseVMInfo := vmv4{ID: vmid, Hostname: vmname, RegionID: region, Cores: 1, Memory: 512,
+		DateCreated: now, Ifaces: ifaceresponse, Disks: diskresponse, State: "running"}
 	mockClient.EXPECT().Send("hosting.vm.info",
 		paramsVMInfo, gomock.Any()).SetArg(2, responseVMInfo).Return(nil).After(list)
 
 	vm, _ := testHosting.VMFromName(vmname)
 
-	expectedIPS := []hosting.IPAddress{{"1", "192.168.1.1", regionstr, hosting.IPVersion(4), vmidstr, "used"}}
-	expectedDisks := []hosting.Disk{{"5", "sysdisk_1", disksize, regionstr, "created", "data", []string{vmidstr}, true}}
+	expectedIPS := []hosting.IPAddress{{ID: "1", IP: "192.168.1.1", RegionID: regionstr,
+		Version: hosting.IPVersion(4), VM: vmidstr, State: "used"}}
+	expectedDisks := []hosting.Disk{{ID: "5", Name: "sysdisk_1", Size: disksize, RegionID: regionstr, State: "created", Type: "data", VM: []string{vmidstr}, BootDisk: true}}
 	expected := hosting.VM{
 		ID:          vmidstr,
 		Hostname:    vmname,
@@ -594,18 +762,20 @@ func TestRenameVM(t *testing.T) {
 		paramsWait, gomock.Any()).SetArg(2, responseWait).Return(nil).After(update)
 
 	paramsVMInfo := []interface{}{vmid}
-	ipsresponse := []iPAddressv4{{1, "192.168.1.1", region, 4, vmid, "used"}}
-	ifaceresponse := []iface{{ipsresponse, region, 1, vmid}}
-	diskresponse := []diskv4{{5, "sysdisk_1", disksizeMB, region, "created", "data", []int{vmid}, true}}
-	responseVMInfo := vmv4{vmid, "NEWNAME", region, "", "", 1, 512, now, ifaceresponse, diskresponse, "running"}
+	ipsresponse := []iPAddressv4{{ID: 1, IP: "192.168.1.1", RegionID: region, Version: 4, VM: vmid, State: "used"}}
+	ifaceresponse := []iface{{IPs: ipsresponse, RegionID: region, ID: 1, VMID: vmid}}
+	diskresponse := []diskv4{{ID: 5, Name: "sysdisk_1", Size: disksizeMB, RegionID: region, State: "created", Type: "data", VM: []int{vmid}, BootDisk: true}}
+	responseVMInfo := vmv4{ID: vmid, Hostname: "NEWNAME", RegionID: region, Cores: 1, Memory: 512,
+		DateCreated: now, Ifaces: ifaceresponse, Disks: diskresponse, State: "running"}
 	mockClient.EXPECT().Send("hosting.vm.info",
 		paramsVMInfo, gomock.Any()).SetArg(2, responseVMInfo).Return(nil).After(wait)
 
 	vmreq := hosting.VM{ID: vmidstr}
 	vm, _ := testHosting.RenameVM(vmreq, "NEWNAME")
 
-	expectedIPS := []hosting.IPAddress{{"1", "192.168.1.1", regionstr, hosting.IPVersion(4), vmidstr, "used"}}
-	expectedDisks := []hosting.Disk{{"5", "sysdisk_1", disksize, regionstr, "created", "data", []string{vmidstr}, true}}
+	expectedIPS := []hosting.IPAddress{{ID: "1", IP: "192.168.1.1", RegionID: regionstr,
+		Version: hosting.IPVersion(4), VM: vmidstr, State: "used"}}
+	expectedDisks := []hosting.Disk{{ID: "5", Name: "sysdisk_1", Size: disksize, RegionID: regionstr, State: "created", Type: "data", VM: []string{vmidstr}, BootDisk: true}}
 	expected := hosting.VM{
 		ID:          vmidstr,
 		Hostname:    "NEWNAME",
@@ -624,3 +794,333 @@ func TestRenameVM(t *testing.T) {
 		t.Errorf("Error, expected %+v, got instead %+v", expected, vm)
 	}
 }
+
+func TestEnableConsole(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	paramsVMUpdate := []interface{}{vmid, map[string]interface{}{"console": true}}
+	responseVMUpdate := Operation{ID: 5, VMID: vmid}
+	update := mockClient.EXPECT().Send("hosting.vm.update",
+		paramsVMUpdate, gomock.Any()).SetArg(2, responseVMUpdate).Return(nil)
+
+	paramsWait := []interface{}{responseVMUpdate.ID}
+	responseWait := operationInfo{responseVMUpdate.ID, "DONE"}
+	wait := mockClient.EXPECT().Send("operation.info",
+		paramsWait, gomock.Any()).SetArg(2, responseWait).Return(nil).After(update)
+
+	paramsVMInfo := []interface{}{vmid}
+	responseVMInfo := vmv4{ID: vmid, Hostname: vmname, RegionID: region, State: "running",
+		Console: 1, ConsoleURL: "console.gandi.net"}
+	mockClient.EXPECT().Send("hosting.vm.info",
+		paramsVMInfo, gomock.Any()).SetArg(2, responseVMInfo).Return(nil).After(wait)
+
+	vm, err := testHosting.EnableConsole(hosting.VM{ID: vmidstr})
+	if err != nil {
+		t.Errorf("Error, %s", err)
+	}
+
+	expected := hosting.ConsoleInfo{Enabled: true, URL: "console.gandi.net"}
+	if vm.Console != expected {
+		t.Errorf("Error, expected %+v, got instead %+v", expected, vm.Console)
+	}
+}
+
+func TestDisableConsole(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	paramsVMUpdate := []interface{}{vmid, map[string]interface{}{"console": false}}
+	responseVMUpdate := Operation{ID: 5, VMID: vmid}
+	update := mockClient.EXPECT().Send("hosting.vm.update",
+		paramsVMUpdate, gomock.Any()).SetArg(2, responseVMUpdate).Return(nil)
+
+	paramsWait := []interface{}{responseVMUpdate.ID}
+	responseWait := operationInfo{responseVMUpdate.ID, "DONE"}
+	wait := mockClient.EXPECT().Send("operation.info",
+		paramsWait, gomock.Any()).SetArg(2, responseWait).Return(nil).After(update)
+
+	paramsVMInfo := []interface{}{vmid}
+	responseVMInfo := vmv4{ID: vmid, Hostname: vmname, RegionID: region, State: "running"}
+	mockClient.EXPECT().Send("hosting.vm.info",
+		paramsVMInfo, gomock.Any()).SetArg(2, responseVMInfo).Return(nil).After(wait)
+
+	vm, err := testHosting.DisableConsole(hosting.VM{ID: vmidstr})
+	if err != nil {
+		t.Errorf("Error, %s", err)
+	}
+
+	if vm.Console.Enabled || vm.Console.URL != "" {
+		t.Errorf("Error, expected console to be disabled, got instead %+v", vm.Console)
+	}
+}
+
+// expectIPAttachDetach registers the calls made by ipAttachDetach
+// and returns the last one
+func expectIPAttachDetach(mockClient *mock.MockV4Caller, op string, vmid, ipid, ifaceid, opid int, after *gomock.Call) *gomock.Call {
+	ipinfo := mockClient.EXPECT().Send("hosting.ip.info",
+		[]interface{}{ipid}, gomock.Any()).SetArg(2, Operation{IfaceID: ifaceid}).Return(nil)
+	if after != nil {
+		ipinfo.After(after)
+	}
+	call := mockClient.EXPECT().Send("hosting.vm."+op,
+		[]interface{}{vmid, ifaceid}, gomock.Any()).SetArg(2, Operation{ID: opid}).Return(nil).After(ipinfo)
+	wait := mockClient.EXPECT().Send("operation.info",
+		[]interface{}{opid}, gomock.Any()).SetArg(2, operationInfo{opid, "DONE"}).Return(nil).After(call)
+	vminfo := mockClient.EXPECT().Send("hosting.vm.info",
+		[]interface{}{vmid}, gomock.Any()).SetArg(2, vmv4{ID: vmid, RegionID: region}).Return(nil).After(wait)
+	return mockClient.EXPECT().Send("hosting.ip.info",
+		[]interface{}{ipid}, gomock.Any()).SetArg(2, iPAddressv4{ID: ipid, RegionID: region, VM: vmid}).Return(nil).After(vminfo)
+}
+
+func TestMoveIP(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	detach := expectIPAttachDetach(mockClient, "iface_detach", 1, 3, 2, 5, nil)
+	expectIPAttachDetach(mockClient, "iface_attach", 2, 3, 2, 6, detach)
+
+	ip := hosting.IPAddress{ID: "3", RegionID: regionstr}
+	from := hosting.VM{ID: "1", RegionID: regionstr}
+	to := hosting.VM{ID: "2", RegionID: regionstr}
+	fromres, tores, ipres, err := testHosting.MoveIP(ip, from, to, hosting.MoveIPOptions{})
+	if err != nil {
+		t.Errorf("Error, %s", err)
+	}
+
+	if fromres.ID != "1" || tores.ID != "2" {
+		t.Errorf("Error, expected VMs 1 and 2, got instead %s and %s", fromres.ID, tores.ID)
+	}
+	if ipres.VM != "2" {
+		t.Errorf("Error, expected IP to be attached to VM 2, got instead %s", ipres.VM)
+	}
+}
+
+func TestMoveIPRollback(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	detach := expectIPAttachDetach(mockClient, "iface_detach", 1, 3, 2, 5, nil)
+
+	ipinfo := mockClient.EXPECT().Send("hosting.ip.info",
+		[]interface{}{3}, gomock.Any()).SetArg(2, Operation{IfaceID: 2}).Return(nil).After(detach)
+	failed := mockClient.EXPECT().Send("hosting.vm.iface_attach",
+		[]interface{}{2, 2}, gomock.Any()).Return(errors.New("VM locked")).After(ipinfo)
+
+	expectIPAttachDetach(mockClient, "iface_attach", 1, 3, 2, 7, failed)
+
+	ip := hosting.IPAddress{ID: "3", RegionID: regionstr}
+	from := hosting.VM{ID: "1", RegionID: regionstr}
+	to := hosting.VM{ID: "2", RegionID: regionstr}
+	_, _, _, err := testHosting.MoveIP(ip, from, to, hosting.MoveIPOptions{})
+
+	expected := "VM locked, IP 3 was attached back to hosting.VM 1"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Error, expected error '%s', got instead '%v'", expected, err)
+	}
+}
+
+func TestMoveIPBadState(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	mockClient.EXPECT().Send("hosting.vm.info",
+		[]interface{}{1}, gomock.Any()).SetArg(2, vmv4{ID: 1, RegionID: region, State: "running"}).Return(nil)
+
+	ip := hosting.IPAddress{ID: "3", RegionID: regionstr}
+	from := hosting.VM{ID: "1", RegionID: regionstr}
+	to := hosting.VM{ID: "2", RegionID: regionstr}
+	_, _, _, err := testHosting.MoveIP(ip, from, to, hosting.MoveIPOptions{FromState: "halted"})
+
+	expected := &HostingError{"MoveIP", "hosting.VM", "State", ErrMismatch}
+	if !reflect.DeepEqual(expected, err) {
+		t.Errorf("Error, expected %+v, got instead %+v", expected, err)
+	}
+}
+
+/* SSH keys */
+
+func TestAddSSHKeyToVM(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	paramsVMInfo := []interface{}{vmid}
+	responseCurrent := vmv4{ID: vmid, Hostname: vmname, Keys: &[]sshkeyv4{{ID: 2, Name: "old"}}}
+	current := mockClient.EXPECT().Send("hosting.vm.info",
+		paramsVMInfo, gomock.Any()).SetArg(2, responseCurrent).Return(nil)
+
+	paramsVMUpdate := []interface{}{vmid, map[string]interface{}{"keys": []int{2, keyid}}}
+	responseVMUpdate := Operation{ID: 5, VMID: vmid}
+	update := mockClient.EXPECT().Send("hosting.vm.update",
+		paramsVMUpdate, gomock.Any()).SetArg(2, responseVMUpdate).Return(nil).After(current)
+
+	responseWait := operationInfo{responseVMUpdate.ID, "DONE"}
+	wait := mockClient.EXPECT().Send("operation.info",
+		[]interface{}{responseVMUpdate.ID}, gomock.Any()).SetArg(2, responseWait).Return(nil).After(update)
+
+	responseVMInfo := vmv4{ID: vmid, Hostname: vmname,
+		Keys: &[]sshkeyv4{{ID: 2, Name: "old"}, {ID: keyid, Name: keyname}}}
+	mockClient.EXPECT().Send("hosting.vm.info",
+		paramsVMInfo, gomock.Any()).SetArg(2, responseVMInfo).Return(nil).After(wait)
+
+	vm, err := testHosting.AddSSHKeyToVM(hosting.VM{ID: vmidstr}, hosting.SSHKey{ID: keyidstr})
+	if err != nil {
+		t.Errorf("Error, %s", err)
+	}
+
+	expected := []string{"old", keyname}
+	if !reflect.DeepEqual(expected, vm.SSHKeys) {
+		t.Errorf("Error, expected %+v, got instead %+v", expected, vm.SSHKeys)
+	}
+}
+
+func TestAddSSHKeyToVMAlreadyPresent(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	responseCurrent := vmv4{ID: vmid, Hostname: vmname, Keys: &[]sshkeyv4{{ID: keyid, Name: keyname}}}
+	mockClient.EXPECT().Send("hosting.vm.info",
+		[]interface{}{vmid}, gomock.Any()).SetArg(2, responseCurrent).Return(nil)
+
+	vm, err := testHosting.AddSSHKeyToVM(hosting.VM{ID: vmidstr}, hosting.SSHKey{ID: keyidstr})
+	if err != nil || len(vm.SSHKeys) != 1 {
+		t.Errorf("Error, expected VM unchanged, got instead %+v (%v)", vm, err)
+	}
+}
+
+func TestAddSSHKeyToVMKeysNotReturned(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	// Without the current keys, no update is sent
+	mockClient.EXPECT().Send("hosting.vm.info",
+		[]interface{}{vmid}, gomock.Any()).SetArg(2, vmv4{ID: vmid, Hostname: vmname}).Return(nil)
+
+	_, err := testHosting.AddSSHKeyToVM(hosting.VM{ID: vmidstr}, hosting.SSHKey{ID: keyidstr})
+	expected := &HostingError{"AddSSHKeyToVM", "hosting.VM", "SSHKeys", ErrNotAvailable}
+	if !reflect.DeepEqual(expected, err) {
+		t.Errorf("Error, expected %+v, got instead %+v", expected, err)
+	}
+}
+
+func TestRemoveSSHKeyFromVM(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	paramsListKey := []interface{}{map[string]string{"name": keyname}}
+	list := mockClient.EXPECT().Send("hosting.ssh.list",
+		paramsListKey, gomock.Any()).SetArg(2, []sshkeyv4{{ID: keyid, Name: keyname}}).Return(nil)
+	info := mockClient.EXPECT().Send("hosting.ssh.info",
+		[]interface{}{keyid}, gomock.Any()).SetArg(2, sshkeyv4{ID: keyid, Name: keyname}).Return(nil).After(list)
+
+	paramsVMInfo := []interface{}{vmid}
+	responseCurrent := vmv4{ID: vmid, Hostname: vmname, Keys: &[]sshkeyv4{{ID: keyid, Name: keyname}}}
+	current := mockClient.EXPECT().Send("hosting.vm.info",
+		paramsVMInfo, gomock.Any()).SetArg(2, responseCurrent).Return(nil).After(info)
+
+	paramsVMUpdate := []interface{}{vmid, map[string]interface{}{"keys": []int{}}}
+	responseVMUpdate := Operation{ID: 5, VMID: vmid}
+	update := mockClient.EXPECT().Send("hosting.vm.update",
+		paramsVMUpdate, gomock.Any()).SetArg(2, responseVMUpdate).Return(nil).After(current)
+
+	responseWait := operationInfo{responseVMUpdate.ID, "DONE"}
+	wait := mockClient.EXPECT().Send("operation.info",
+		[]interface{}{responseVMUpdate.ID}, gomock.Any()).SetArg(2, responseWait).Return(nil).After(update)
+
+	mockClient.EXPECT().Send("hosting.vm.info",
+		paramsVMInfo, gomock.Any()).SetArg(2, vmv4{ID: vmid, Hostname: vmname}).Return(nil).After(wait)
+
+	vm, err := testHosting.RemoveSSHKeyFromVM(hosting.VM{ID: vmidstr}, hosting.SSHKey{Name: keyname})
+	if err != nil || len(vm.SSHKeys) != 0 {
+		t.Errorf("Error, expected no keys left, got instead %+v (%v)", vm, err)
+	}
+}
+
+func TestUpdateVMDescription(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	description := "Web server\ntags: env=prod"
+	paramsVMUpdate := []interface{}{vmid, map[string]interface{}{"description": description}}
+	responseVMUpdate := Operation{ID: 5, VMID: vmid}
+	update := mockClient.EXPECT().Send("hosting.vm.update",
+		paramsVMUpdate, gomock.Any()).SetArg(2, responseVMUpdate).Return(nil)
+
+	paramsWait := []interface{}{responseVMUpdate.ID}
+	responseWait := operationInfo{responseVMUpdate.ID, "DONE"}
+	wait := mockClient.EXPECT().Send("operation.info",
+		paramsWait, gomock.Any()).SetArg(2, responseWait).Return(nil).After(update)
+
+	responseVMInfo := vmv4{ID: vmid, Hostname: vmname, RegionID: region, Farm: "web", Description: description}
+	mockClient.EXPECT().Send("hosting.vm.info",
+		[]interface{}{vmid}, gomock.Any()).SetArg(2, responseVMInfo).Return(nil).After(wait)
+
+	vm, err := testHosting.UpdateVMDescription(hosting.VM{ID: vmidstr}, description)
+	if err != nil {
+		t.Fatalf("Error, %s", err)
+	}
+	expected := hosting.Tags{"env": "prod", hosting.FarmTag: "web"}
+	if !reflect.DeepEqual(expected, hosting.VMTags(vm)) {
+		t.Errorf("Error, expected %v, got instead %v", expected, hosting.VMTags(vm))
+	}
+}
+
+func TestListVMsSummary(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mock.NewMockV4Caller(mockCtrl)
+	testHosting := Newv4Hosting(mockClient)
+
+	// No hosting.vm.info is needed for names and states
+	paramsVMList := []interface{}{map[string]interface{}{"sort_by": "-memory"}}
+	responseVMList := []vmv4{
+		{ID: 1, Hostname: "web1", RegionID: region, Memory: 1024, State: "running"},
+		{ID: 2, Hostname: "db1", RegionID: region, Memory: 4096, State: "halted"},
+		{ID: 3, Hostname: "web2", RegionID: region, Memory: 1024, State: "running"},
+	}
+	mockClient.EXPECT().Send("hosting.vm.list",
+		paramsVMList, gomock.Any()).SetArg(2, responseVMList).Return(nil)
+
+	vmfilter := hosting.VMFilter{Options: hosting.ListOptions{
+		SortBy:     hosting.SortByMemory,
+		Descending: true,
+		Fields:     []string{"Hostname", "State"},
+	}}
+	vms, err := testHosting.ListVMs(vmfilter)
+	if err != nil {
+		t.Fatalf("Error, %s", err)
+	}
+	var names []string
+	for _, vm := range vms {
+		names = append(names, vm.Hostname)
+	}
+	expected := []string{"db1", "web1", "web2"}
+	if !reflect.DeepEqual(expected, names) {
+		t.Errorf("Error, expected %v, got instead %v", expected, names)
+	}
+
+	_, err = testHosting.ListVMs(hosting.VMFilter{Options: hosting.ListOptions{SortBy: hosting.SortBySize}})
+	if err == nil {
+		t.Errorf("Error, expected error sorting VMs by size")
+	}
+}
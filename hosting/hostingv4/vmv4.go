@@ -22,25 +22,40 @@ type vmv4 struct {
 	Ifaces      []iface   `xmlrpc:"ifaces"`
 	Disks       []diskv4  `xmlrpc:"disks"`
 	State       string    `xmlrpc:"state"`
+	Console     int       `xmlrpc:"console"`
+	ConsoleURL  string    `xmlrpc:"console_url"`
+
+	// keys is not a documented field of hosting.vm.info,
+	// it is nil when the API does not return it
+	Keys *[]sshkeyv4 `xmlrpc:"keys"`
 }
 
 type vmSpecv4 struct {
-	RegionID  int    `xmlrpc:"datacenter_id"`
-	Hostname  string `xmlrpc:"hostname"`
-	Farm      string `xmlrpc:"farm"`
-	Memory    int    `xmlrpc:"memory"`
-	Cores     int    `xmlrpc:"cores"`
-	SSHKeysID []int  `xmlrpc:"keys"`
-	Login     string `xmlrpc:"login"`
-	Password  string `xmlrpc:"password"`
+	RegionID    int    `xmlrpc:"datacenter_id"`
+	Hostname    string `xmlrpc:"hostname"`
+	Farm        string `xmlrpc:"farm"`
+	Description string `xmlrpc:"description"`
+	Memory      int    `xmlrpc:"memory"`
+	Cores       int    `xmlrpc:"cores"`
+	SSHKeysID   []int  `xmlrpc:"keys"`
+	Login       string `xmlrpc:"login"`
+	Password    string `xmlrpc:"password"`
 }
 
 type vmFilterv4 struct {
-	RegionID int    `xmlrpc:"datacenter_id"`
-	Farm     string `xmlrpc:"farm"`
-	Hostname string `xmlrpc:"hostname"`
-	ID       int    `xmlrpc:"id"`
-	State    string `xmlrpc:"state"`
+	RegionID interface{} `xmlrpc:"datacenter_id"`
+	Farm     string      `xmlrpc:"farm"`
+	Hostname string      `xmlrpc:"hostname"`
+	ID       interface{} `xmlrpc:"id"`
+	State    interface{} `xmlrpc:"state"`
+	SortBy   string      `xmlrpc:"sort_by"`
+}
+
+// Fields of the API to sort VMs by
+var vmSortKeysv4 = map[string]string{
+	hosting.SortByName:   "hostname",
+	hosting.SortByDate:   "date_created",
+	hosting.SortByMemory: "memory",
 }
 
 // CreateVMWithExistingDiskAndIP creates a hosting.VM from a hosting.VMSpec if a valid hosting.IPAddress and hosting.Disk are given,
@@ -53,6 +68,11 @@ func (h Hostingv4) CreateVMWithExistingDiskAndIP(vm hosting.VMSpec, ip hosting.I
 	if err != nil {
 		return hosting.VM{}, hosting.IPAddress{}, hosting.Disk{}, err
 	}
+	if vm.CheckQuota {
+		if err := h.checkQuota(vm, 0, 0); err != nil {
+			return hosting.VM{}, hosting.IPAddress{}, hosting.Disk{}, err
+		}
+	}
 
 	// call api to get the iface id that corresponds to the ip
 	ifaceid, err := h.ifaceIDFromIPID(ipid)
@@ -86,10 +106,15 @@ func (h Hostingv4) CreateVMWithExistingDisk(vm hosting.VMSpec, version hosting.I
 	if err != nil {
 		return hosting.VM{}, hosting.IPAddress{}, hosting.Disk{}, err
 	}
+	if vm.CheckQuota {
+		if err := h.checkQuota(vm, 0, 1); err != nil {
+			return hosting.VM{}, hosting.IPAddress{}, hosting.Disk{}, err
+		}
+	}
 
 	vmspecmap["sys_disk_id"] = diskid
 	vmspecmap["ip_version"] = int(version)
-	vmspecmap["bandwidth"] = hosting.DefaultBandwidth
+	vmspecmap["bandwidth"] = bandwidthOrDefault(vm.Bandwidth)
 
 	vmid, err := h.createVMFromVMSpecMap(vmspecmap)
 	if err != nil {
@@ -113,6 +138,11 @@ func (h Hostingv4) CreateVMWithExistingIP(vm hosting.VMSpec, image hosting.DiskI
 	if err != nil {
 		return hosting.VM{}, hosting.IPAddress{}, hosting.Disk{}, err
 	}
+	if vm.CheckQuota {
+		if err := h.checkQuota(vm, int(diskSize), 0); err != nil {
+			return hosting.VM{}, hosting.IPAddress{}, hosting.Disk{}, err
+		}
+	}
 
 	// Get the corresponding ifaceid of the ip
 	ifaceid, err := h.ifaceIDFromIPID(ipid)
@@ -128,26 +158,7 @@ func (h Hostingv4) CreateVMWithExistingIP(vm hosting.VMSpec, image hosting.DiskI
 	diskparam, _ := structToMap(diskspec)
 
 	params := []interface{}{vmspecmap, diskparam, imageid}
-	response := []Operation{}
-	log.Printf("[INFO] Creating hosting.VM %s...", vmspecmap["hostname"])
-	err = h.Send("hosting.vm.create_from", params, &response)
-	if err != nil {
-		return hosting.VM{}, hosting.IPAddress{}, hosting.Disk{}, err
-	}
-
-	vmop := response[2]
-	// Wait for vm operation to finish, disk operation
-	// will always end before
-	if err = h.waitForOp(vmop); err != nil {
-		return hosting.VM{}, hosting.IPAddress{}, hosting.Disk{}, err
-	}
-	log.Printf("[INFO] hosting.VM %s(ID: %d) created!", vmspecmap["hostname"], response[2].VMID)
-	vmRes, err := h.vmFromID(vmop.ID)
-	vmRes.SSHKeys = vm.SSHKeysID
-	if err != nil {
-		return hosting.VM{}, hosting.IPAddress{}, hosting.Disk{}, err
-	}
-	return vmRes, vmRes.Ips[0], vmRes.Disks[0], nil
+	return h.createVMFrom(vm, params, ifaceid)
 }
 
 // CreateVM creates a hosting.VM from scratch, creating also a system disk and an ip address
@@ -158,9 +169,14 @@ func (h Hostingv4) CreateVM(vm hosting.VMSpec, image hosting.DiskImage, version
 	if err != nil {
 		return hosting.VM{}, hosting.IPAddress{}, hosting.Disk{}, err
 	}
+	if vm.CheckQuota {
+		if err := h.checkQuota(vm, int(diskSize), 1); err != nil {
+			return hosting.VM{}, hosting.IPAddress{}, hosting.Disk{}, err
+		}
+	}
 
 	vmspecmap["ip_version"] = int(version)
-	vmspecmap["bandwidth"] = hosting.DefaultBandwidth
+	vmspecmap["bandwidth"] = bandwidthOrDefault(vm.Bandwidth)
 	diskspec := diskSpecv4{
 		// Docs say datacenter_id is an optional parameter
 		RegionID: vmspecmap["datacenter_id"].(int),
@@ -168,28 +184,146 @@ func (h Hostingv4) CreateVM(vm hosting.VMSpec, image hosting.DiskImage, version
 	}
 	diskparam, _ := structToMap(diskspec)
 	params := []interface{}{vmspecmap, diskparam, imageid}
+	return h.createVMFrom(vm, params, 0)
+}
+
+// createVMFrom creates a VM, its system Disk and, if `ifaceid` is 0,
+// its interface with hosting.vm.create_from
+//
+// If `vm.Rollback` is set, the resources created are deleted when
+// the creation fails, see rollbackVM
+func (h Hostingv4) createVMFrom(vm hosting.VMSpec, params []interface{}, ifaceid int) (hosting.VM, hosting.IPAddress, hosting.Disk, error) {
+	vmspecmap := params[0].(map[string]interface{})
 	response := []Operation{}
 	log.Printf("[INFO] Creating hosting.VM %s...", vmspecmap["hostname"])
-	err = h.Send("hosting.vm.create_from", params, &response)
+	err := h.Send("hosting.vm.create_from", params, &response)
 	if err != nil {
 		return hosting.VM{}, hosting.IPAddress{}, hosting.Disk{}, err
 	}
+	fail := func(err error) (hosting.VM, hosting.IPAddress, hosting.Disk, error) {
+		if vm.Rollback {
+			err = h.rollbackVM(response, ifaceid, err)
+		}
+		return hosting.VM{}, hosting.IPAddress{}, hosting.Disk{}, err
+	}
 
+	// Operations on the interface, the disk and the vm
+	if len(response) < 3 {
+		return fail(fmt.Errorf("Expected 3 operations creating hosting.VM %s, got %d",
+			vmspecmap["hostname"], len(response)))
+	}
 	vmop := response[2]
 	// Wait for vm operation to finish, disk operation
 	// will always end before
 	if err = h.waitForOp(vmop); err != nil {
-		return hosting.VM{}, hosting.IPAddress{}, hosting.Disk{}, err
+		return fail(err)
 	}
-	log.Printf("[INFO] hosting.VM %s(ID: %d) created!", vmspecmap["hostname"], response[2].VMID)
-	vmRes, err := h.vmFromID(vmop.ID)
-	vmRes.SSHKeys = vm.SSHKeysID
+	log.Printf("[INFO] hosting.VM %s(ID: %d) created!", vmspecmap["hostname"], vmop.VMID)
+	vmRes, err := h.vmFromID(vmop.VMID)
 	if err != nil {
-		return hosting.VM{}, hosting.IPAddress{}, hosting.Disk{}, err
+		return fail(err)
 	}
+	vmRes.SSHKeys = vm.SSHKeysID
 	return vmRes, vmRes.Ips[0], vmRes.Disks[0], nil
 }
 
+// rollbackVM deletes the resources created by the operations of
+// hosting.vm.create_from, `ifaceid` is the interface given by the
+// caller, it is detached from the VM and kept
+//
+// The VM is stopped and deleted first, its system Disk and its
+// interface are deleted with it. If the VM is known not to exist,
+// the Disk and the interface are deleted one by one, otherwise a
+// VM that could not be read is reported as left with them
+func (h Hostingv4) rollbackVM(ops []Operation, ifaceid int, cause error) error {
+	var vmid, diskid, newiface int
+	for _, op := range ops {
+		if op.VMID != 0 {
+			vmid = op.VMID
+		}
+		if op.DiskID != 0 {
+			diskid = op.DiskID
+		}
+		if op.IfaceID != 0 && op.IfaceID != ifaceid {
+			newiface = op.IfaceID
+		}
+	}
+
+	rberr := &hosting.RollbackError{Err: cause}
+	deleted := func(resource string, id int, err error) {
+		name := fmt.Sprintf("%s %d", resource, id)
+		if err != nil {
+			log.Printf("[WARN] Rollback: could not delete %s: %s", name, err)
+			rberr.Left = append(rberr.Left, name)
+			return
+		}
+		rberr.Deleted = append(rberr.Deleted, name)
+	}
+	log.Printf("[WARN] Creation failed, deleting what was created: %s", cause)
+
+	if vmid != 0 {
+		vm, err := h.vmFromID(vmid)
+		switch {
+		case err == nil:
+			err = h.deleteCreatedVM(vm, ifaceid)
+		case h.vmNotFound(vmid):
+			// Only the Disk and the interface were created
+			vmid = 0
+		}
+		if vmid != 0 {
+			deleted("VM", vmid, err)
+			// The Disk and the interface of the VM go with it,
+			// they cannot be deleted while it exists
+			if diskid != 0 {
+				deleted("Disk", diskid, err)
+			}
+			if newiface != 0 {
+				deleted("interface", newiface, err)
+			}
+			return rberr
+		}
+	}
+	if diskid != 0 {
+		deleted("Disk", diskid, h.DeleteDisk(hosting.Disk{ID: strconv.Itoa(diskid)}))
+	}
+	if newiface != 0 {
+		deleted("interface", newiface, h.deleteIface(newiface))
+	}
+	return rberr
+}
+
+// vmNotFound reports whether the VM `vmid` is known not to
+// exist, an error listing the VMs does not prove it
+func (h Hostingv4) vmNotFound(vmid int) bool {
+	vms, err := h.ListVMs(hosting.VMFilter{
+		ID:      strconv.Itoa(vmid),
+		Options: hosting.ListOptions{Fields: []string{"ID"}},
+	})
+	return err == nil && len(vms) == 0
+}
+
+// deleteCreatedVM stops and deletes a VM being rolled back,
+// `ifaceid` is detached first so that it is not deleted
+func (h Hostingv4) deleteCreatedVM(vm hosting.VM, ifaceid int) error {
+	if vm.State == "running" {
+		if err := h.StopVM(vm); err != nil {
+			return err
+		}
+	}
+	if ifaceid != 0 {
+		vmid, _ := strconv.Atoi(vm.ID)
+		response := Operation{}
+		err := h.Send("hosting.vm.iface_detach", []interface{}{vmid, ifaceid}, &response)
+		if err != nil {
+			return err
+		}
+		if err = h.waitForOp(response); err != nil {
+			return err
+		}
+	}
+	return h.DeleteVM(vm)
+}
+
 // AttachDisk attaches a hosting.Disk to a hosting.VM, both objects must already exist
 // and be in the same hosting.Region
 func (h Hostingv4) AttachDisk(vm hosting.VM, disk hosting.Disk) (hosting.VM, hosting.Disk, error) {
@@ -305,6 +439,66 @@ func (h Hostingv4) ipAttachDetach(vm hosting.VM, ip hosting.IPAddress, op string
 	return vmRes, ipRes, nil
 }
 
+// MoveIP moves an IP from hosting.VM `from` to hosting.VM `to`, the three
+// objects must be in the same hosting.Region
+//
+// If the IP cannot be attached to `to`, it is attached back to `from`,
+// the error returned then describes the result of the rollback
+func (h Hostingv4) MoveIP(ip hosting.IPAddress, from hosting.VM, to hosting.VM,
+	opts hosting.MoveIPOptions) (hosting.VM, hosting.VM, hosting.IPAddress, error) {
+	var fn = "MoveIP"
+	if from.RegionID != ip.RegionID || to.RegionID != ip.RegionID {
+		return hosting.VM{}, hosting.VM{}, hosting.IPAddress{}, &HostingError{fn, "hosting.VM/hosting.IPAddress", "RegionID", ErrMismatch}
+	}
+	if err := h.checkVMState(fn, from, opts.FromState); err != nil {
+		return hosting.VM{}, hosting.VM{}, hosting.IPAddress{}, err
+	}
+	if err := h.checkVMState(fn, to, opts.ToState); err != nil {
+		return hosting.VM{}, hosting.VM{}, hosting.IPAddress{}, err
+	}
+
+	log.Printf("[INFO] Moving IP %s from hosting.VM %s to %s...", ip.IP, from.ID, to.ID)
+	fromRes, _, err := h.DetachIP(from, ip)
+	if err != nil {
+		return hosting.VM{}, hosting.VM{}, hosting.IPAddress{}, err
+	}
+
+	toRes, ipRes, err := h.AttachIP(to, ip)
+	if err != nil {
+		log.Printf("[WARN] Attaching IP %s to hosting.VM %s failed, attaching it back to %s", ip.IP, to.ID, from.ID)
+		if _, _, rberr := h.AttachIP(from, ip); rberr != nil {
+			return hosting.VM{}, hosting.VM{}, hosting.IPAddress{},
+				fmt.Errorf("%s, rollback to hosting.VM %s failed, IP %s is detached: %s", err, from.ID, ip.ID, rberr)
+		}
+		return hosting.VM{}, hosting.VM{}, hosting.IPAddress{},
+			fmt.Errorf("%s, IP %s was attached back to hosting.VM %s", err, ip.ID, from.ID)
+	}
+
+	log.Printf("[INFO] IP %s moved to hosting.VM %s!", ip.IP, to.ID)
+	return fromRes, toRes, ipRes, nil
+}
+
+// checkVMState returns an error if the current state of `vm`
+// is not `state`, no check is done if `state` is empty
+func (h Hostingv4) checkVMState(fn string, vm hosting.VM, state string) error {
+	if state == "" {
+		return nil
+	}
+	vmid, err := strconv.Atoi(vm.ID)
+	if err != nil {
+		return internalParseError("hosting.VM", "ID")
+	}
+	current, err := h.vmFromID(vmid)
+	if err != nil {
+		return err
+	}
+	if current.State != state {
+		log.Printf("[WARN] hosting.VM %s is %s, expected %s", vm.ID, current.State, state)
+		return &HostingError{fn, "hosting.VM", "State", ErrMismatch}
+	}
+	return nil
+}
+
 // StartVM starts a stopped hosting.VM
 func (h Hostingv4) StartVM(vm hosting.VM) error {
 	var fn = "start"
@@ -325,8 +519,8 @@ func (h Hostingv4) RebootVM(vm hosting.VM) error {
 
 // DeleteVM deletes a vm
 //
-// Add cascade option?
-// Automatically stop vm before deleting?
+// The vm must be stopped, use hosting.DestroyVM to stop it
+// and handle its disks and ips
 func (h Hostingv4) DeleteVM(vm hosting.VM) error {
 	var fn = "delete"
 	return h.opVM(vm, fn)
@@ -369,17 +563,25 @@ func (h Hostingv4) ListVMs(vmfilter hosting.VMFilter) ([]hosting.VM, error) {
 	}
 
 	var vms []hosting.VM
+	full := vmfilter.Options.NeedsFullVM()
 	for _, vmv4 := range response {
+		vm := fromVMv4(vmv4)
 		// vm list does not a contain the full description
 		// call vm info to get a vm's interfaces and disks
-		vm, err := h.vmFromID(vmv4.ID)
-		if err != nil {
-			log.Printf("[WARN] Error getting %s (ID: %s) information, excluded from list: %s", vm.Hostname, vm.ID, err)
+		if full {
+			vm, err = h.vmFromID(vmv4.ID)
+			if err != nil {
+				log.Printf("[WARN] Error getting %s (ID: %d) information, excluded from list: %s", vmv4.Hostname, vmv4.ID, err)
+				continue
+			}
+		}
+		if !vmfilter.Match(vm) {
 			continue
 		}
 		vms = append(vms, vm)
 	}
-	return vms, nil
+	// The order of the API is kept for equal keys
+	return vms, hosting.SortVMs(vms, vmfilter.Options)
 }
 
 // VMFromName is a helper function to get a hosting.VM given its name
@@ -394,7 +596,7 @@ func (h Hostingv4) VMFromName(name string) (hosting.VM, error) {
 		return hosting.VM{}, err
 	}
 	if len(vms) < 1 {
-		return hosting.VM{}, fmt.Errorf("hosting.VM '%s' does not exist", name)
+		return hosting.VM{}, hosting.ErrVMNotFound
 	}
 
 	return vms[0], nil
@@ -426,6 +628,92 @@ func (h Hostingv4) RenameVM(vm hosting.VM, newname string) (hosting.VM, error) {
 	return h.updateVM(vm, vmupdate)
 }
 
+// UpdateVMDescription replaces the description of a hosting.VM
+func (h Hostingv4) UpdateVMDescription(vm hosting.VM, description string) (hosting.VM, error) {
+	vmupdate := map[string]interface{}{"description": description}
+	return h.updateVM(vm, vmupdate)
+}
+
+// EnableConsole enables the emergency console of a hosting.VM,
+// the URL to connect to it is returned in `hosting.VM.Console`
+func (h Hostingv4) EnableConsole(vm hosting.VM) (hosting.VM, error) {
+	vmupdate := map[string]interface{}{"console": true}
+	return h.updateVM(vm, vmupdate)
+}
+
+// DisableConsole disables the emergency console of a hosting.VM
+func (h Hostingv4) DisableConsole(vm hosting.VM) (hosting.VM, error) {
+	vmupdate := map[string]interface{}{"console": false}
+	return h.updateVM(vm, vmupdate)
+}
+
+// AddSSHKeyToVM adds `key` to the keys of a hosting.VM
+//
+// `key` is looked up by name if its ID is not set. The keys of a
+// VM are only known if hosting.vm.info returns them, which is not
+// documented, an ErrNotAvailable error is returned otherwise
+func (h Hostingv4) AddSSHKeyToVM(vm hosting.VM, key hosting.SSHKey) (hosting.VM, error) {
+	return h.updateVMKeys("AddSSHKeyToVM", vm, key, true)
+}
+
+// RemoveSSHKeyFromVM removes `key` from the keys of a hosting.VM
+//
+// `key` is looked up by name if its ID is not set, see AddSSHKeyToVM
+func (h Hostingv4) RemoveSSHKeyFromVM(vm hosting.VM, key hosting.SSHKey) (hosting.VM, error) {
+	return h.updateVMKeys("RemoveSSHKeyFromVM", vm, key, false)
+}
+
+// updateVMKeys adds or removes a key from the current keys of a hosting.VM,
+// the whole list of keys is sent to the API
+//
+// The keys field of hosting.vm.info and hosting.vm.update is not part
+// of the v4 API reference, the update is refused when hosting.vm.info
+// does not return the current keys so that none of them is dropped
+func (h Hostingv4) updateVMKeys(fn string, vm hosting.VM, key hosting.SSHKey, add bool) (hosting.VM, error) {
+	if vm.ID == "" {
+		return hosting.VM{}, &HostingError{fn, "hosting.VM", "ID", ErrNotProvided}
+	}
+	vmid, err := strconv.Atoi(vm.ID)
+	if err != nil {
+		return hosting.VM{}, &HostingError{fn, "hosting.VM", "ID", ErrParse}
+	}
+	keyid, err := h.sshKeyID(fn, key)
+	if err != nil {
+		return hosting.VM{}, err
+	}
+
+	current := vmv4{}
+	err = h.Send("hosting.vm.info", []interface{}{vmid}, &current)
+	if err != nil {
+		return hosting.VM{}, err
+	}
+
+	if current.Keys == nil {
+		return hosting.VM{}, &HostingError{fn, "hosting.VM", "SSHKeys", ErrNotAvailable}
+	}
+
+	keys := []int{}
+	found := false
+	for _, k := range *current.Keys {
+		if k.ID == keyid {
+			found = true
+			if !add {
+				continue
+			}
+		}
+		keys = append(keys, k.ID)
+	}
+	if found == add {
+		return fromVMv4(current), nil
+	}
+	if add {
+		keys = append(keys, keyid)
+	}
+
+	vmupdate := map[string]interface{}{"keys": keys}
+	return h.updateVM(vm, vmupdate)
+}
+
 // Common function for update operations
 func (h Hostingv4) updateVM(vm hosting.VM, vmupdate map[string]interface{}) (hosting.VM, error) {
 	var fn = "UpdateVM"
@@ -553,22 +841,28 @@ func (h Hostingv4) createVMFromVMSpecMap(vmspecmap map[string]interface{}) (int,
 
 // Hosting VMFilter -> VMFilter v4
 func toVMFilterv4(vmfilter hosting.VMFilter) (vmFilterv4, error) {
-	region := toInt(vmfilter.RegionID)
-	if region == -1 {
+	region, ok := toIDFilter(vmfilter.RegionID, vmfilter.RegionIDs)
+	if !ok {
 		return vmFilterv4{}, internalParseError("VMFilter", "RegionID")
 	}
 
-	id := toInt(vmfilter.ID)
-	if id == -1 {
+	id, ok := toIDFilter(vmfilter.ID, vmfilter.IDs)
+	if !ok {
 		return vmFilterv4{}, internalParseError("VMFilter", "ID")
 	}
 
+	sortby, ok := toSortByv4(vmfilter.Options, vmSortKeysv4)
+	if !ok {
+		return vmFilterv4{}, &HostingError{"ListVMs", "VMFilter", "Options", ErrNotAvailable}
+	}
+
 	return vmFilterv4{
 		RegionID: region,
 		ID:       id,
-		Hostname: vmfilter.Hostname,
+		Hostname: toNameFilter(vmfilter.Hostname),
 		Farm:     vmfilter.Farm,
-		State:    vmfilter.State,
+		State:    toStringFilter(vmfilter.State, vmfilter.States),
+		SortBy:   sortby,
 	}, nil
 }
 
@@ -580,22 +874,29 @@ func (h Hostingv4) toVMSpecv4(vm hosting.VMSpec) (vmSpecv4, error) {
 	}
 	var keys []int
 	for _, key := range vm.SSHKeysID {
-		sshkey := h.KeyFromName(key)
+		sshkey, err := h.KeyFromName(key)
+		if err == hosting.ErrKeyNotFound {
+			return vmSpecv4{}, errors.New("Key '" + key + "' does not exist")
+		}
+		if err != nil {
+			return vmSpecv4{}, err
+		}
 		keyid, err := strconv.Atoi(sshkey.ID)
 		if err != nil {
-			return vmSpecv4{}, errors.New("Key '" + key + "' does not exist")
+			return vmSpecv4{}, internalParseError("hosting.SSHKey", "ID")
 		}
 		keys = append(keys, keyid)
 	}
 	return vmSpecv4{
-		RegionID:  regionid,
-		Hostname:  vm.Hostname,
-		Farm:      vm.Farm,
-		Cores:     vm.Cores,
-		Memory:    vm.Memory,
-		SSHKeysID: keys,
-		Login:     vm.Login,
-		Password:  vm.Password,
+		RegionID:    regionid,
+		Hostname:    vm.Hostname,
+		Farm:        vm.Farm,
+		Description: vm.Description,
+		Cores:       vm.Cores,
+		Memory:      vm.Memory,
+		SSHKeysID:   keys,
+		Login:       vm.Login,
+		Password:    vm.Password,
 	}, nil
 }
 
@@ -606,14 +907,18 @@ func fromVMv4(vm vmv4) hosting.VM {
 	var ips []hosting.IPAddress
 	// v4 works with interfaces, extract the ips from them
 	for _, iface := range vm.Ifaces {
-		for _, ip := range iface.IPs {
-			ips = append(ips, toIPAddress(ip))
-		}
+		ips = append(ips, ipsFromIface(iface)...)
 	}
 	var disks []hosting.Disk
 	for _, disk := range vm.Disks {
 		disks = append(disks, fromDiskv4(disk))
 	}
+	var keys []string
+	if vm.Keys != nil {
+		for _, key := range *vm.Keys {
+			keys = append(keys, key.Name)
+		}
+	}
 	return hosting.VM{
 		ID:          id,
 		Hostname:    vm.Hostname,
@@ -625,5 +930,11 @@ func fromVMv4(vm vmv4) hosting.VM {
 		DateCreated: vm.DateCreated,
 		Ips:         ips,
 		Disks:       disks,
-		State:       vm.State}
+		State:       vm.State,
+		SSHKeys:     keys,
+		Console: hosting.ConsoleInfo{
+			Enabled: vm.Console != 0,
+			URL:     vm.ConsoleURL,
+		},
+	}
 }
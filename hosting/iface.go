@@ -0,0 +1,74 @@
+package hosting
+
+// InterfaceManager represents a service capable of manipulating
+// the network interfaces that hold IPs in Gandi's platform
+//
+// It is an optional interface, not every version of the API
+// exposes interfaces, use a type assertion on a Hosting value
+// to check if it is available. IPManager should be preferred
+// for everything that can be expressed in terms of IPs
+type InterfaceManager interface {
+
+	// ListInterfaces return a list of Interfaces, filtered with
+	// the options given in the InterfaceFilter
+	//
+	// An unset field in `ifacefilter` is ignored when making the
+	// request
+	ListInterfaces(ifacefilter InterfaceFilter) ([]Interface, error)
+
+	// InterfaceFromIP returns the Interface that holds
+	// the IPAddress given
+	InterfaceFromIP(ip IPAddress) (Interface, error)
+
+	// UpdateInterfaceBandwidth changes the bandwidth of an
+	// Interface, `bandwidth` being in kbps
+	UpdateInterfaceBandwidth(iface Interface, bandwidth float32) (Interface, error)
+
+	// AttachIPToInterface adds an existing IPAddress to an
+	// Interface, so that the Interface holds more than one IP
+	AttachIPToInterface(iface Interface, ip IPAddress) (Interface, error)
+
+	// MoveInterface detaches an Interface from the VM it is
+	// attached to, if any, and attaches it to `vm`
+	//
+	// Both objects must be in the same Region
+	MoveInterface(iface Interface, vm VM) (Interface, error)
+}
+
+// Interface represents a network interface, every
+// IPAddress belongs to one
+type Interface struct {
+	// ID of the object in the API
+	ID string
+
+	// ID of the Region the Interface is in
+	RegionID string
+
+	// The VM this Interface is attached to
+	VM string
+
+	// Bandwidth of the Interface in kbps
+	Bandwidth float32
+
+	// List of IPs of the Interface
+	IPs []IPAddress
+
+	// Type of Interface: public, private
+	Type string
+
+	// ID of the Vlan of a private Interface
+	Vlan string
+
+	// State of the Interface:
+	// free, being_created, created, used, deleted
+	State string
+}
+
+// InterfaceFilter is used to list Interfaces,
+// filtered with the options provided
+type InterfaceFilter struct {
+	ID       string
+	RegionID string
+	VMID     string
+	Type     string
+}
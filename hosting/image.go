@@ -1,5 +1,12 @@
 package hosting
 
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // ImageManager represents a service capable of getting information about
 // Gandi Disk images
 type ImageManager interface {
@@ -11,16 +18,128 @@ type ImageManager interface {
 	// ListImagesInRegion returns every Image that can be found in
 	// the Region provided
 	ListImagesInRegion(region Region) ([]DiskImage, error)
+
+	// ListImages returns the images matching `imagefilter`,
+	// the most recent ones first
+	//
+	// See ImageFilter for the matching rules
+	ListImages(imagefilter ImageFilter) ([]DiskImage, error)
+
+	// LatestImage returns the most recent image matching
+	// `imagefilter`, e.g. the latest Debian in a Region
+	LatestImage(imagefilter ImageFilter) (DiskImage, error)
+
+	// CreateImageFromDisk creates a private image named `name`
+	// from a copy of `disk`, usually a configured system Disk
+	//
+	// The image can be used like any other DiskImage to create
+	// Disks and VMs in the Region of `disk`
+	CreateImageFromDisk(disk Disk, name string) (DiskImage, error)
+
+	// ListPrivateImages returns every private image created
+	// with CreateImageFromDisk
+	ListPrivateImages() ([]DiskImage, error)
+
+	// DeleteImage deletes a private image, public images
+	// cannot be deleted
+	DeleteImage(image DiskImage) error
 }
 
 // DiskImage is an image offered by Gandi
 // with an OS, used to create system Disks
-//
-// TODO: Add kernel version and other info
 type DiskImage struct {
 	ID       string
 	DiskID   string
 	RegionID string
 	Name     string
 	Size     int
+
+	// OS family, e.g. Debian, Ubuntu, CentOS
+	OS string
+
+	// Version of the OS, e.g. 9, 18.04
+	Version string
+
+	// Architecture, e.g. x86-64, x86-32
+	Architecture string
+
+	// Version of the kernel the image boots with
+	KernelVersion string
+
+	// Visibility of the image: all, private
+	Visibility string
+
+	// Time of creation of the image
+	DateCreated time.Time
+}
+
+// ImageFilter is used to search images, an unset field is ignored
+//
+// String fields are compared without case, `Name` matches every
+// image whose name contains all the words given and `Version`
+// matches every version starting with the value given
+type ImageFilter struct {
+	RegionID     string
+	Name         string
+	OS           string
+	Version      string
+	Architecture string
+	Visibility   string
+}
+
+// Match tells if `image` matches the filter
+func (f ImageFilter) Match(image DiskImage) bool {
+	if f.RegionID != "" && f.RegionID != image.RegionID {
+		return false
+	}
+	name := strings.ToLower(image.Name)
+	for _, word := range strings.Fields(strings.ToLower(f.Name)) {
+		if !strings.Contains(name, word) {
+			return false
+		}
+	}
+	if f.OS != "" && !strings.EqualFold(f.OS, image.OS) {
+		return false
+	}
+	if f.Version != "" && !strings.HasPrefix(strings.ToLower(image.Version), strings.ToLower(f.Version)) {
+		return false
+	}
+	if f.Architecture != "" && !strings.EqualFold(f.Architecture, image.Architecture) {
+		return false
+	}
+	if f.Visibility != "" && !strings.EqualFold(f.Visibility, image.Visibility) {
+		return false
+	}
+	return true
+}
+
+// SortImagesByVersion sorts `images` from the most recent to the
+// oldest, comparing their OS versions and then their creation dates
+func SortImagesByVersion(images []DiskImage) {
+	sort.SliceStable(images, func(i, j int) bool {
+		if c := compareVersions(images[i].Version, images[j].Version); c != 0 {
+			return c > 0
+		}
+		return images[i].DateCreated.After(images[j].DateCreated)
+	})
+}
+
+// compareVersions compares two dotted versions, numeric parts
+// are compared as numbers, e.g. 10 > 9 and 18.04 > 16.10
+func compareVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, erra := strconv.Atoi(pa[i])
+		nb, errb := strconv.Atoi(pb[i])
+		switch {
+		case erra == nil && errb == nil && na != nb:
+			if na > nb {
+				return 1
+			}
+			return -1
+		case (erra != nil || errb != nil) && pa[i] != pb[i]:
+			return strings.Compare(pa[i], pb[i])
+		}
+	}
+	return len(pa) - len(pb)
 }
@@ -0,0 +1,359 @@
+package hosting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// InventoryVersion is the version of the Inventory format
+// written by ExportInventory
+//
+// Inventories with a greater version cannot be read
+const InventoryVersion = 1
+
+// Formats an Inventory can be written in
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// Inventory is a snapshot of every resource of an account,
+// meant to be saved and used to recreate the resources later
+//
+// Resources reference each other by name, or by address for IPs,
+// instead of IDs, and Regions are referenced by datacenter code,
+// so that an Inventory stays valid once the resources are recreated
+type Inventory struct {
+	Version int             `json:"version" yaml:"version"`
+	SSHKeys []InventoryKey  `json:"ssh_keys,omitempty" yaml:"ssh_keys,omitempty"`
+	Vlans   []InventoryVlan `json:"vlans,omitempty" yaml:"vlans,omitempty"`
+	Disks   []InventoryDisk `json:"disks,omitempty" yaml:"disks,omitempty"`
+	IPs     []InventoryIP   `json:"ips,omitempty" yaml:"ips,omitempty"`
+	VMs     []InventoryVM   `json:"vms,omitempty" yaml:"vms,omitempty"`
+}
+
+// InventoryKey is an SSHKey in an Inventory
+type InventoryKey struct {
+	Name        string `json:"name" yaml:"name"`
+	Value       string `json:"value" yaml:"value"`
+	Fingerprint string `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`
+}
+
+// InventoryVlan is a Vlan in an Inventory
+type InventoryVlan struct {
+	Name    string `json:"name" yaml:"name"`
+	Region  string `json:"region" yaml:"region"`
+	Subnet  string `json:"subnet,omitempty" yaml:"subnet,omitempty"`
+	Gateway string `json:"gateway,omitempty" yaml:"gateway,omitempty"`
+}
+
+// InventoryDisk is a Disk in an Inventory, its size is in GB
+type InventoryDisk struct {
+	Name   string `json:"name" yaml:"name"`
+	Region string `json:"region" yaml:"region"`
+	Size   int    `json:"size" yaml:"size"`
+	Kernel string `json:"kernel,omitempty" yaml:"kernel,omitempty"`
+}
+
+// InventoryIP is an IPAddress in an Inventory, `Vlan` is
+// the name of the Vlan of a private IP
+type InventoryIP struct {
+	IP      string `json:"ip" yaml:"ip"`
+	Region  string `json:"region" yaml:"region"`
+	Version int    `json:"version" yaml:"version"`
+	Vlan    string `json:"vlan,omitempty" yaml:"vlan,omitempty"`
+	Reverse string `json:"reverse,omitempty" yaml:"reverse,omitempty"`
+}
+
+// InventoryVM is a VM in an Inventory
+//
+// `Disks` contains the names of its Disks, the boot Disk first,
+// `IPs` the addresses of its IPs and `SSHKeys` the names of the
+// keys it was created with, when they are known. The tags of
+// the VM are kept in its `Description`, see VMTags
+type InventoryVM struct {
+	Hostname    string   `json:"hostname" yaml:"hostname"`
+	Region      string   `json:"region" yaml:"region"`
+	Farm        string   `json:"farm,omitempty" yaml:"farm,omitempty"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Cores       int      `json:"cores" yaml:"cores"`
+	Memory      int      `json:"memory" yaml:"memory"`
+	Disks       []string `json:"disks,omitempty" yaml:"disks,omitempty"`
+	IPs         []string `json:"ips,omitempty" yaml:"ips,omitempty"`
+	SSHKeys     []string `json:"ssh_keys,omitempty" yaml:"ssh_keys,omitempty"`
+}
+
+// InventorySpecs contains the specs needed to recreate
+// the resources of an Inventory
+//
+// Attachments are not part of the specs, they are found
+// in the VMs of the Inventory. Private IPs cannot be
+// described by an IPSpec and are returned as they are,
+// see CreatePrivateIP
+type InventorySpecs struct {
+	SSHKeys    []InventoryKey
+	Vlans      []VlanSpec
+	Disks      []DiskSpec
+	IPs        []IPSpec
+	PrivateIPs []InventoryIP
+	VMs        []VMSpec
+}
+
+// ExportInventory returns an Inventory of every SSHKey, Vlan,
+// Disk, IP and VM of the account, each sorted by name, or by
+// address for IPs, so that two exports can be compared
+func ExportInventory(h Hosting) (Inventory, error) {
+	inventory := Inventory{Version: InventoryVersion}
+
+	regions, err := h.ListRegions()
+	if err != nil {
+		return Inventory{}, err
+	}
+	codes := map[string]string{}
+	for _, region := range regions {
+		codes[region.ID] = region.Name
+	}
+	code := func(regionid string) (string, error) {
+		if c, ok := codes[regionid]; ok {
+			return c, nil
+		}
+		return "", fmt.Errorf("Unknown Region '%s'", regionid)
+	}
+
+	keys, err := h.ListKeys()
+	if err != nil {
+		return Inventory{}, err
+	}
+	for _, key := range keys {
+		inventory.SSHKeys = append(inventory.SSHKeys, InventoryKey{key.Name, key.Value, key.Fingerprint})
+	}
+
+	vlans, err := h.ListVlans(VlanFilter{})
+	if err != nil {
+		return Inventory{}, err
+	}
+	// Name of the Vlan of each private IP, by ID
+	vlanNames := map[string]string{}
+	for _, vlan := range vlans {
+		region, err := code(vlan.RegionID)
+		if err != nil {
+			return Inventory{}, err
+		}
+		members, err := h.ListVlanMembers(vlan)
+		if err != nil {
+			return Inventory{}, err
+		}
+		for _, ip := range members.IPs {
+			vlanNames[ip.ID] = vlan.Name
+		}
+		inventory.Vlans = append(inventory.Vlans, InventoryVlan{vlan.Name, region, vlan.Subnet, vlan.Gateway})
+	}
+
+	disks, err := h.ListAllDisks()
+	if err != nil {
+		return Inventory{}, err
+	}
+	for _, disk := range disks {
+		region, err := code(disk.RegionID)
+		if err != nil {
+			return Inventory{}, err
+		}
+		inventory.Disks = append(inventory.Disks, InventoryDisk{disk.Name, region, disk.Size, disk.Kernel})
+	}
+
+	ips, err := h.ListIPs(IPFilter{})
+	if err != nil {
+		return Inventory{}, err
+	}
+	for _, ip := range ips {
+		region, err := code(ip.RegionID)
+		if err != nil {
+			return Inventory{}, err
+		}
+		inventory.IPs = append(inventory.IPs,
+			InventoryIP{ip.IP, region, int(ip.Version), vlanNames[ip.ID], ip.Reverse})
+	}
+
+	vms, err := h.ListAllVMs()
+	if err != nil {
+		return Inventory{}, err
+	}
+	for _, vm := range vms {
+		region, err := code(vm.RegionID)
+		if err != nil {
+			return Inventory{}, err
+		}
+		ivm := InventoryVM{
+			Hostname:    vm.Hostname,
+			Region:      region,
+			Farm:        vm.Farm,
+			Description: vm.Description,
+			Cores:       vm.Cores,
+			Memory:      vm.Memory,
+			SSHKeys:     vm.SSHKeys,
+		}
+		for _, disk := range vm.Disks {
+			ivm.Disks = append(ivm.Disks, disk.Name)
+		}
+		for _, ip := range vm.Ips {
+			ivm.IPs = append(ivm.IPs, ip.IP)
+		}
+		inventory.VMs = append(inventory.VMs, ivm)
+	}
+
+	sort.SliceStable(inventory.SSHKeys, func(i, j int) bool {
+		return inventory.SSHKeys[i].Name < inventory.SSHKeys[j].Name
+	})
+	sort.SliceStable(inventory.Vlans, func(i, j int) bool {
+		return inventory.Vlans[i].Name < inventory.Vlans[j].Name
+	})
+	sort.SliceStable(inventory.Disks, func(i, j int) bool {
+		return inventory.Disks[i].Name < inventory.Disks[j].Name
+	})
+	sort.SliceStable(inventory.IPs, func(i, j int) bool {
+		return inventory.IPs[i].IP < inventory.IPs[j].IP
+	})
+	sort.SliceStable(inventory.VMs, func(i, j int) bool {
+		return inventory.VMs[i].Hostname < inventory.VMs[j].Hostname
+	})
+	return inventory, nil
+}
+
+// Marshal encodes the Inventory in `format`
+func (inv Inventory) Marshal(format string) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		return json.MarshalIndent(inv, "", "  ")
+	case FormatYAML:
+		return yaml.Marshal(inv)
+	}
+	return nil, errors.New("Unknown format '" + format + "'")
+}
+
+// UnmarshalInventory decodes an Inventory written in `format`
+// and checks that it is valid, see Validate
+func UnmarshalInventory(data []byte, format string) (Inventory, error) {
+	inventory := Inventory{}
+	var err error
+	switch format {
+	case FormatJSON:
+		err = json.Unmarshal(data, &inventory)
+	case FormatYAML:
+		err = yaml.Unmarshal(data, &inventory)
+	default:
+		err = errors.New("Unknown format '" + format + "'")
+	}
+	if err != nil {
+		return Inventory{}, err
+	}
+	return inventory, inventory.Validate()
+}
+
+// Validate checks the version of the Inventory
+// and that every reference can be resolved
+func (inv Inventory) Validate() error {
+	if inv.Version < 1 || inv.Version > InventoryVersion {
+		return fmt.Errorf("Unsupported Inventory version %d", inv.Version)
+	}
+
+	keys := map[string]bool{}
+	for _, key := range inv.SSHKeys {
+		keys[key.Name] = true
+	}
+	vlans := map[string]bool{}
+	for _, vlan := range inv.Vlans {
+		vlans[vlan.Name] = true
+	}
+	disks := map[string]bool{}
+	for _, disk := range inv.Disks {
+		disks[disk.Name] = true
+	}
+	ips := map[string]bool{}
+	for _, ip := range inv.IPs {
+		if ip.Vlan != "" && !vlans[ip.Vlan] {
+			return fmt.Errorf("IP '%s' references unknown Vlan '%s'", ip.IP, ip.Vlan)
+		}
+		ips[ip.IP] = true
+	}
+
+	for _, vm := range inv.VMs {
+		for _, disk := range vm.Disks {
+			if !disks[disk] {
+				return fmt.Errorf("VM '%s' references unknown Disk '%s'", vm.Hostname, disk)
+			}
+		}
+		for _, ip := range vm.IPs {
+			if !ips[ip] {
+				return fmt.Errorf("VM '%s' references unknown IP '%s'", vm.Hostname, ip)
+			}
+		}
+		for _, key := range vm.SSHKeys {
+			if !keys[key] {
+				return fmt.Errorf("VM '%s' references unknown SSHKey '%s'", vm.Hostname, key)
+			}
+		}
+	}
+	return nil
+}
+
+// Specs returns the specs to recreate the resources of the
+// Inventory, `regions` are used to resolve the datacenter codes
+func (inv Inventory) Specs(regions []Region) (InventorySpecs, error) {
+	ids := map[string]string{}
+	for _, region := range regions {
+		ids[region.Name] = region.ID
+	}
+	id := func(code string) (string, error) {
+		if i, ok := ids[code]; ok {
+			return i, nil
+		}
+		return "", fmt.Errorf("Unknown Region '%s'", code)
+	}
+
+	specs := InventorySpecs{SSHKeys: inv.SSHKeys}
+	for _, vlan := range inv.Vlans {
+		region, err := id(vlan.Region)
+		if err != nil {
+			return InventorySpecs{}, err
+		}
+		specs.Vlans = append(specs.Vlans, VlanSpec{vlan.Name, vlan.Gateway, vlan.Subnet, region})
+	}
+	for _, disk := range inv.Disks {
+		region, err := id(disk.Region)
+		if err != nil {
+			return InventorySpecs{}, err
+		}
+		specs.Disks = append(specs.Disks, DiskSpec{RegionID: region, Name: disk.Name, Size: disk.Size})
+	}
+	for _, ip := range inv.IPs {
+		if ip.Vlan != "" {
+			specs.PrivateIPs = append(specs.PrivateIPs, ip)
+			continue
+		}
+		region, err := id(ip.Region)
+		if err != nil {
+			return InventorySpecs{}, err
+		}
+		specs.IPs = append(specs.IPs, IPSpec{RegionID: region, Version: IPVersion(ip.Version)})
+	}
+	for _, vm := range inv.VMs {
+		region, err := id(vm.Region)
+		if err != nil {
+			return InventorySpecs{}, err
+		}
+		specs.VMs = append(specs.VMs, VMSpec{
+			RegionID:    region,
+			Hostname:    vm.Hostname,
+			Farm:        vm.Farm,
+			Description: vm.Description,
+			Cores:       vm.Cores,
+			Memory:      vm.Memory,
+			SSHKeysID:   vm.SSHKeys,
+		})
+	}
+	return specs, nil
+}
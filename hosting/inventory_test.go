@@ -0,0 +1,130 @@
+package hosting
+
+import (
+	"reflect"
+	"testing"
+)
+
+// accountHosting returns a small account with
+// one VM using every other kind of resource
+type accountHosting struct {
+	Hosting
+}
+
+func (h accountHosting) ListRegions() ([]Region, error) {
+	return []Region{{ID: "1", Name: "FR-SD5"}, {ID: "2", Name: "LU-BI1"}}, nil
+}
+
+func (h accountHosting) ListKeys() ([]SSHKey, error) {
+	return []SSHKey{
+		{ID: "8", Name: "deploy", Value: "ssh-ed25519 BBBB deploy", Fingerprint: "cc:dd"},
+		{ID: "7", Name: "admin", Value: "ssh-ed25519 AAAA admin", Fingerprint: "aa:bb"},
+	}, nil
+}
+
+func (h accountHosting) ListVlans(vlanfilter VlanFilter) ([]Vlan, error) {
+	return []Vlan{{ID: "3", Name: "backend", RegionID: "1", Subnet: "10.0.0.0/24", Gateway: "10.0.0.1"}}, nil
+}
+
+func (h accountHosting) ListVlanMembers(vlan Vlan) (VlanMembers, error) {
+	return VlanMembers{IPs: []IPAddress{{ID: "11", IP: "10.0.0.2", RegionID: "1", Version: IPv4, Vlan: "3"}}}, nil
+}
+
+func (h accountHosting) ListAllDisks() ([]Disk, error) {
+	return []Disk{
+		{ID: "21", Name: "tmp", RegionID: "1", Size: 5, Kernel: "raw"},
+		{ID: "20", Name: "sys_web1", RegionID: "1", Size: 10, Kernel: "raw"},
+	}, nil
+}
+
+func (h accountHosting) ListIPs(ipfilter IPFilter) ([]IPAddress, error) {
+	return []IPAddress{
+		{ID: "11", IP: "10.0.0.2", RegionID: "1", Version: IPv4, VM: "1", State: "used"},
+		{ID: "10", IP: "1.2.3.4", RegionID: "1", Version: IPv4, VM: "1", State: "used"},
+	}, nil
+}
+
+func (h accountHosting) ListAllVMs() ([]VM, error) {
+	return []VM{{
+		Hostname:    "web1",
+		RegionID:    "1",
+		Description: "Web server\ntags: team=web",
+		Cores:       2,
+		Memory:      2048,
+		Disks:       []Disk{{Name: "sys_web1"}},
+		Ips:         []IPAddress{{IP: "1.2.3.4"}, {IP: "10.0.0.2"}},
+		SSHKeys:     []string{"admin"},
+	}}, nil
+}
+
+func TestInventoryRoundTrip(t *testing.T) {
+	h := accountHosting{}
+	inventory, err := ExportInventory(h)
+	if err != nil {
+		t.Fatalf("Error, %s", err)
+	}
+	if inventory.IPs[0].Vlan != "" || inventory.IPs[1].Vlan != "backend" || inventory.VMs[0].Region != "FR-SD5" {
+		t.Errorf("Error, references were not resolved in %+v", inventory)
+	}
+	if inventory.SSHKeys[0].Name != "admin" || inventory.Disks[0].Name != "sys_web1" || inventory.IPs[0].IP != "1.2.3.4" {
+		t.Errorf("Error, expected sorted resources in %+v", inventory)
+	}
+
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		data, err := inventory.Marshal(format)
+		if err != nil {
+			t.Fatalf("Error, %s", err)
+		}
+		loaded, err := UnmarshalInventory(data, format)
+		if err != nil {
+			t.Fatalf("Error, %s", err)
+		}
+		if !reflect.DeepEqual(inventory, loaded) {
+			t.Errorf("Error, expected %+v, got instead %+v from %s", inventory, loaded, format)
+		}
+	}
+}
+
+func TestInventoryValidate(t *testing.T) {
+	inventory, _ := ExportInventory(accountHosting{})
+
+	inventory.VMs[0].Disks = []string{"missing"}
+	if inventory.Validate() == nil {
+		t.Errorf("Error, expected error for an unknown Disk")
+	}
+
+	_, err := UnmarshalInventory([]byte(`{"version": 2}`), FormatJSON)
+	if err == nil {
+		t.Errorf("Error, expected error for a newer version")
+	}
+}
+
+func TestInventorySpecs(t *testing.T) {
+	inventory, _ := ExportInventory(accountHosting{})
+	regions, _ := accountHosting{}.ListRegions()
+
+	specs, err := inventory.Specs(regions)
+	if err != nil {
+		t.Fatalf("Error, %s", err)
+	}
+
+	expectedVM := VMSpec{
+		RegionID:    "1",
+		Hostname:    "web1",
+		Description: "Web server\ntags: team=web",
+		Cores:       2,
+		Memory:      2048,
+		SSHKeysID:   []string{"admin"},
+	}
+	if len(specs.VMs) != 1 || !reflect.DeepEqual(expectedVM, specs.VMs[0]) {
+		t.Errorf("Error, expected %+v, got instead %+v", expectedVM, specs.VMs)
+	}
+	if len(specs.IPs) != 1 || len(specs.PrivateIPs) != 1 {
+		t.Errorf("Error, expected one public and one private IP, got instead %+v", specs)
+	}
+
+	_, err = inventory.Specs([]Region{{ID: "2", Name: "LU-BI1"}})
+	if err == nil {
+		t.Errorf("Error, expected error for an unknown Region")
+	}
+}
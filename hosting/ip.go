@@ -1,5 +1,7 @@
 package hosting
 
+import "time"
+
 // IPVersion represents the possible versions of an ip
 //
 // limits possible input parameters
@@ -20,13 +22,23 @@ type IPManager interface {
 
 	// CreateIP creates an IPv4 or an IPv6 in the Region given
 	//
-	// The IP created can only be public
+	// The IP created can only be public, its bandwidth
+	// is DefaultBandwidth
 	CreateIP(region Region, version IPVersion) (IPAddress, error)
 
+	// CreateIPFromSpec creates a public IP from a given IPSpec
+	//
+	// It allows choosing the bandwidth of the IP
+	CreateIPFromSpec(ip IPSpec) (IPAddress, error)
+
 	// CreatePrivateIP creates a private IPv4 within a Vlan
 	//
 	// Region is inferred from the vlan provided, so it is mandatory
 	// that it contains a valid RegionID
+	// Like CreateIP, its bandwidth is DefaultBandwidth, as IPAM
+	// allocates addresses with only a vlan, see UpdateIPBandwidth
+	// to change it
+	// See IPAM to get a free address in the vlan
 	CreatePrivateIP(vlan Vlan, ip string) (IPAddress, error)
 
 	// ListIPs return a list of IPs, filtered with the options
@@ -41,6 +53,19 @@ type IPManager interface {
 	//
 	// If the operation was successful a nil error is returned
 	DeleteIP(ip IPAddress) error
+
+	// UpdateIPBandwidth changes the bandwidth of the IP given,
+	// `bandwidth` being in kbps
+	//
+	// In the case of Hostingv4, the bandwidth belongs to the
+	// interface, so every IP sharing it is affected
+	UpdateIPBandwidth(ip IPAddress, bandwidth float32) (IPAddress, error)
+
+	// SetReverseDNS sets the reverse DNS name (PTR record)
+	// of the IP given
+	//
+	// It returns the updated IPAddress once the change is done
+	SetReverseDNS(ip IPAddress, name string) (IPAddress, error)
 }
 
 // IPAddress represents a Gandi IP
@@ -66,13 +91,58 @@ type IPAddress struct {
 
 	// State of the IP: used, free
 	State string
+
+	// Reverse DNS name of the IP
+	Reverse string
+
+	// ID of the Vlan of a private IP, empty for public IPs,
+	// ListIPs only sets it if IPFilter.WithVlan is set
+	Vlan string
+
+	// Date the IP was created
+	DateCreated time.Time
+}
+
+// IsPrivate reports whether the IP is a private IP of a Vlan,
+// its Vlan must be known, see IPFilter.WithVlan
+func (ip IPAddress) IsPrivate() bool {
+	return ip.Vlan != ""
+}
+
+// IPSpec contains the parameters to create a new public IP
+//
+// `RegionID` and `Version` are mandatory, `Bandwidth`
+// defaults to DefaultBandwidth if not provided
+type IPSpec struct {
+	RegionID string
+	Version  IPVersion
+
+	// Bandwidth in kbps
+	Bandwidth float32
 }
 
 // IPFilter is used to list IPs, filtered
 // with the parameters provided
+//
+// An IP must match every field set, a single value and a list
+// can both be given, e.g. `ID` and `IDs`. `Reverse` can be a
+// pattern, see MatchName
 type IPFilter struct {
 	ID       string
 	RegionID string
 	Version  IPVersion
 	IP       string
+	Reverse  string
+
+	// The IP must be one of these
+	IDs       []string
+	RegionIDs []string
+	States    []string
+
+	// Range of creation dates
+	Created DateRange
+
+	// Set the Vlan of the private IPs returned,
+	// which can take another request
+	WithVlan bool
 }
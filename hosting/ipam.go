@@ -0,0 +1,182 @@
+package hosting
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+)
+
+var (
+	// ErrOutOfSubnet indicates that an IP does not belong to the subnet of a Vlan,
+	// or that it is the network or broadcast address of the subnet
+	ErrOutOfSubnet = errors.New("IP out of Vlan subnet")
+
+	// ErrReserved indicates that an IP is the gateway of a Vlan or lies
+	// within one of the reserved ranges given to the IPAM
+	ErrReserved = errors.New("IP reserved")
+
+	// ErrInUse indicates that an IP is already allocated
+	ErrInUse = errors.New("IP already in use")
+
+	// ErrNoFreeIP indicates that every address of a Vlan subnet is allocated or reserved
+	ErrNoFreeIP = errors.New("No free IP in Vlan subnet")
+)
+
+// IPRange is an inclusive range of IPv4 addresses,
+// a single address can be given by setting only `First`
+type IPRange struct {
+	First string
+	Last  string
+}
+
+// IPAM manages the private addresses of a Vlan
+//
+// It uses an IPManager to know which addresses are already
+// allocated, an address is considered free if it is inside
+// the Vlan subnet and it is neither the network, broadcast
+// or gateway address nor part of a reserved range
+//
+// The IPAM does not hold any state between calls, two
+// concurrent allocations can still pick the same address
+type IPAM struct {
+	ipm      IPManager
+	vlan     Vlan
+	subnet   *net.IPNet
+	reserved [][2]uint32
+}
+
+// NewIPAM creates an IPAM for `vlan`, whose `Subnet` must be
+// a valid IPv4 CIDR, addresses in `reserved` are never allocated
+func NewIPAM(ipm IPManager, vlan Vlan, reserved ...IPRange) (*IPAM, error) {
+	_, subnet, err := net.ParseCIDR(vlan.Subnet)
+	if err != nil || subnet.IP.To4() == nil {
+		return nil, errors.New("Vlan '" + vlan.Name + "' does not have a valid IPv4 subnet")
+	}
+
+	var ranges [][2]uint32
+	for _, r := range reserved {
+		first, ok := ipToUint32(r.First)
+		if !ok {
+			return nil, errors.New("Bad reserved range start '" + r.First + "'")
+		}
+		last := first
+		if r.Last != "" {
+			if last, ok = ipToUint32(r.Last); !ok || last < first {
+				return nil, errors.New("Bad reserved range end '" + r.Last + "'")
+			}
+		}
+		ranges = append(ranges, [2]uint32{first, last})
+	}
+
+	return &IPAM{ipm, vlan, subnet, ranges}, nil
+}
+
+// Allocated returns the private IPs of the Vlan that
+// already exist, other Vlans can use the same subnet
+func (a *IPAM) Allocated() ([]IPAddress, error) {
+	ips, err := a.ipm.ListIPs(IPFilter{RegionID: a.vlan.RegionID, Version: IPv4, WithVlan: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var allocated []IPAddress
+	for _, ip := range ips {
+		if ip.Vlan == a.vlan.ID && a.subnet.Contains(net.ParseIP(ip.IP)) {
+			allocated = append(allocated, ip)
+		}
+	}
+	return allocated, nil
+}
+
+// Validate checks that `ip` can be allocated in the Vlan
+func (a *IPAM) Validate(ip string) error {
+	allocated, err := a.Allocated()
+	if err != nil {
+		return err
+	}
+	return a.validate(ip, allocated)
+}
+
+// NextFree returns the lowest address of the Vlan subnet
+// that can be allocated
+func (a *IPAM) NextFree() (string, error) {
+	allocated, err := a.Allocated()
+	if err != nil {
+		return "", err
+	}
+
+	network := binary.BigEndian.Uint32(a.subnet.IP.To4())
+	ones, bits := a.subnet.Mask.Size()
+	size := uint32(1) << uint(bits-ones)
+	for n := network + 1; n < network+size-1; n++ {
+		ip := uint32ToIP(n)
+		if a.validate(ip, allocated) == nil {
+			return ip, nil
+		}
+	}
+	return "", ErrNoFreeIP
+}
+
+// Allocate creates a private IP in the Vlan with the
+// next free address
+func (a *IPAM) Allocate() (IPAddress, error) {
+	ip, err := a.NextFree()
+	if err != nil {
+		return IPAddress{}, err
+	}
+	return a.ipm.CreatePrivateIP(a.vlan, ip)
+}
+
+// AllocateIP creates a private IP in the Vlan with address
+// `ip`, after checking it can be allocated
+func (a *IPAM) AllocateIP(ip string) (IPAddress, error) {
+	if err := a.Validate(ip); err != nil {
+		return IPAddress{}, err
+	}
+	return a.ipm.CreatePrivateIP(a.vlan, ip)
+}
+
+func (a *IPAM) validate(ip string, allocated []IPAddress) error {
+	n, ok := ipToUint32(ip)
+	if !ok || !a.subnet.Contains(net.ParseIP(ip)) {
+		return ErrOutOfSubnet
+	}
+
+	network := binary.BigEndian.Uint32(a.subnet.IP.To4())
+	ones, bits := a.subnet.Mask.Size()
+	broadcast := network + (uint32(1) << uint(bits-ones)) - 1
+	if n == network || n == broadcast {
+		return ErrOutOfSubnet
+	}
+
+	if gw, ok := ipToUint32(a.vlan.Gateway); ok && gw == n {
+		return ErrReserved
+	}
+	for _, r := range a.reserved {
+		if n >= r[0] && n <= r[1] {
+			return ErrReserved
+		}
+	}
+
+	for _, used := range allocated {
+		if u, ok := ipToUint32(used.IP); ok && u == n {
+			return ErrInUse
+		}
+	}
+	return nil
+}
+
+// ipToUint32 converts a dotted IPv4 address to its integer value
+func ipToUint32(ip string) (uint32, bool) {
+	parsed := net.ParseIP(ip).To4()
+	if parsed == nil {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(parsed), true
+}
+
+func uint32ToIP(n uint32) string {
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, n)
+	return ip.String()
+}
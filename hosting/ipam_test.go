@@ -0,0 +1,110 @@
+package hosting
+
+import (
+	"reflect"
+	"testing"
+)
+
+// fakeIPManager implements the IPManager calls used by the IPAM,
+// any other call panics
+type fakeIPManager struct {
+	IPManager
+	ips     []IPAddress
+	created []string
+}
+
+func (f *fakeIPManager) ListIPs(ipfilter IPFilter) ([]IPAddress, error) {
+	return f.ips, nil
+}
+
+func (f *fakeIPManager) CreatePrivateIP(vlan Vlan, ip string) (IPAddress, error) {
+	f.created = append(f.created, ip)
+	return IPAddress{IP: ip, RegionID: vlan.RegionID, Version: IPv4}, nil
+}
+
+var testVlan = Vlan{
+	ID:       "1",
+	Name:     "vlan1",
+	Gateway:  "192.168.0.1",
+	Subnet:   "192.168.0.0/29",
+	RegionID: "1",
+}
+
+func TestNewIPAMBadSubnet(t *testing.T) {
+	vlan := testVlan
+	vlan.Subnet = "2001:db8::/64"
+	_, err := NewIPAM(&fakeIPManager{}, vlan)
+	if err == nil {
+		t.Errorf("Error, expected error for a non IPv4 subnet")
+	}
+}
+
+func TestIPAMNextFree(t *testing.T) {
+	ipm := &fakeIPManager{ips: []IPAddress{
+		{IP: "192.168.0.2", Vlan: "1"},
+		{IP: "192.168.0.3", Vlan: "1"},
+		// outside of the subnet, ignored
+		{IP: "192.168.1.4", Vlan: "1"},
+		// another Vlan with the same subnet, ignored
+		{IP: "192.168.0.4", Vlan: "2"},
+		// a public IP, ignored
+		{IP: "192.168.0.4"},
+	}}
+	// Only .4 and .6 are free once the reservation is applied
+	ipam, _ := NewIPAM(ipm, testVlan, IPRange{First: "192.168.0.5"})
+
+	ip, err := ipam.NextFree()
+	if err != nil || ip != "192.168.0.4" {
+		t.Errorf("Error, expected 192.168.0.4, got instead %s (%v)", ip, err)
+	}
+}
+
+func TestIPAMNoFreeIP(t *testing.T) {
+	ipm := &fakeIPManager{ips: []IPAddress{{IP: "192.168.0.2", Vlan: "1"}}}
+	ipam, _ := NewIPAM(ipm, testVlan, IPRange{First: "192.168.0.3", Last: "192.168.0.6"})
+
+	_, err := ipam.Allocate()
+	if err != ErrNoFreeIP {
+		t.Errorf("Error, expected %v, got instead %v", ErrNoFreeIP, err)
+	}
+	if len(ipm.created) > 0 {
+		t.Errorf("Error, expected no IP to be created, got %v", ipm.created)
+	}
+}
+
+func TestIPAMValidate(t *testing.T) {
+	ipm := &fakeIPManager{ips: []IPAddress{{IP: "192.168.0.2", Vlan: "1"}}}
+	ipam, _ := NewIPAM(ipm, testVlan, IPRange{First: "192.168.0.5", Last: "192.168.0.6"})
+
+	tests := map[string]error{
+		"192.168.0.0": ErrOutOfSubnet,
+		"192.168.0.7": ErrOutOfSubnet,
+		"192.168.1.3": ErrOutOfSubnet,
+		"notanip":     ErrOutOfSubnet,
+		"192.168.0.1": ErrReserved,
+		"192.168.0.6": ErrReserved,
+		"192.168.0.2": ErrInUse,
+		"192.168.0.3": nil,
+	}
+	for ip, expected := range tests {
+		if err := ipam.Validate(ip); err != expected {
+			t.Errorf("Error, expected %v for %s, got instead %v", expected, ip, err)
+		}
+	}
+}
+
+func TestIPAMAllocate(t *testing.T) {
+	ipm := &fakeIPManager{}
+	ipam, _ := NewIPAM(ipm, testVlan)
+
+	ip, _ := ipam.Allocate()
+	expected := IPAddress{IP: "192.168.0.2", RegionID: "1", Version: IPv4}
+	if !reflect.DeepEqual(expected, ip) {
+		t.Errorf("Error, expected %+v, got instead %+v", expected, ip)
+	}
+
+	_, err := ipam.AllocateIP("192.168.0.1")
+	if err != ErrReserved {
+		t.Errorf("Error, expected %v, got instead %v", ErrReserved, err)
+	}
+}
@@ -0,0 +1,106 @@
+package hosting
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Keys the lists of resources can be sorted by
+const (
+	SortByName   = "name"
+	SortByDate   = "date"
+	SortBySize   = "size"
+	SortByMemory = "memory"
+)
+
+// Fields of a VM that are only known by requesting the
+// VM itself, see ListOptions
+const (
+	FieldDisks   = "Disks"
+	FieldIPs     = "Ips"
+	FieldSSHKeys = "SSHKeys"
+	FieldConsole = "Console"
+)
+
+// ListOptions defines the order and the content of the
+// resources returned by ListVMs and ListDisks
+type ListOptions struct {
+	// Key to sort by: SortByName, SortByDate, SortBySize for
+	// Disks or SortByMemory for VMs. The order of the API is
+	// kept if empty
+	SortBy string
+
+	// Sort in descending order
+	Descending bool
+
+	// Fields of the VMs needed, by name, e.g. Hostname, State,
+	// every field is returned if empty
+	//
+	// Unless FieldDisks, FieldIPs, FieldSSHKeys or FieldConsole
+	// is needed, ListVMs gets the VMs from a single request and
+	// those fields are left empty
+	Fields []string
+}
+
+// NeedsFullVM reports whether the fields of the options
+// can only be known by requesting each VM
+func (o ListOptions) NeedsFullVM() bool {
+	if len(o.Fields) == 0 {
+		return true
+	}
+	for _, field := range o.Fields {
+		switch field {
+		case FieldDisks, FieldIPs, FieldSSHKeys, FieldConsole:
+			return true
+		}
+	}
+	return false
+}
+
+// SortVMs sorts `vms` following the options, the sort is stable
+func SortVMs(vms []VM, opts ListOptions) error {
+	var less func(a, b VM) bool
+	switch opts.SortBy {
+	case "":
+		return nil
+	case SortByName:
+		less = func(a, b VM) bool { return a.Hostname < b.Hostname }
+	case SortByDate:
+		less = func(a, b VM) bool { return a.DateCreated.Before(b.DateCreated) }
+	case SortByMemory:
+		less = func(a, b VM) bool { return a.Memory < b.Memory }
+	default:
+		return fmt.Errorf("VMs cannot be sorted by %s", opts.SortBy)
+	}
+	sort.SliceStable(vms, func(i, j int) bool {
+		if opts.Descending {
+			return less(vms[j], vms[i])
+		}
+		return less(vms[i], vms[j])
+	})
+	return nil
+}
+
+// SortDisks sorts `disks` following the options, the sort is stable
+func SortDisks(disks []Disk, opts ListOptions) error {
+	var less func(a, b Disk) bool
+	switch opts.SortBy {
+	case "":
+		return nil
+	case SortByName:
+		less = func(a, b Disk) bool { return a.Name < b.Name }
+	case SortByDate:
+		less = func(a, b Disk) bool { return a.DateCreated.Before(b.DateCreated) }
+	case SortBySize:
+		less = func(a, b Disk) bool { return a.Size < b.Size }
+	default:
+		return fmt.Errorf("Disks cannot be sorted by %s", opts.SortBy)
+	}
+	sort.SliceStable(disks, func(i, j int) bool {
+		if opts.Descending {
+			return less(disks[j], disks[i])
+		}
+		return less(disks[i], disks[j])
+	})
+	return nil
+}
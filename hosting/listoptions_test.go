@@ -0,0 +1,52 @@
+package hosting
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSortDisks(t *testing.T) {
+	day := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	disks := []Disk{
+		{Name: "c", Size: 10, DateCreated: day},
+		{Name: "a", Size: 20, DateCreated: day.AddDate(0, 0, 2)},
+		{Name: "b", Size: 10, DateCreated: day.AddDate(0, 0, 1)},
+	}
+	tests := []struct {
+		opts     ListOptions
+		expected []string
+	}{
+		{ListOptions{}, []string{"c", "a", "b"}},
+		{ListOptions{SortBy: SortByName}, []string{"a", "b", "c"}},
+		{ListOptions{SortBy: SortByDate, Descending: true}, []string{"a", "b", "c"}},
+		// Stable, c stays before b
+		{ListOptions{SortBy: SortBySize}, []string{"c", "b", "a"}},
+	}
+	for _, test := range tests {
+		sorted := append([]Disk{}, disks...)
+		if err := SortDisks(sorted, test.opts); err != nil {
+			t.Fatalf("Error, %s", err)
+		}
+		var names []string
+		for _, disk := range sorted {
+			names = append(names, disk.Name)
+		}
+		if !reflect.DeepEqual(test.expected, names) {
+			t.Errorf("Error, expected %v for %+v, got instead %v", test.expected, test.opts, names)
+		}
+	}
+
+	if err := SortDisks(disks, ListOptions{SortBy: SortByMemory}); err == nil {
+		t.Errorf("Error, expected error sorting Disks by memory")
+	}
+}
+
+func TestNeedsFullVM(t *testing.T) {
+	if !(ListOptions{}).NeedsFullVM() || !(ListOptions{Fields: []string{"Hostname", FieldIPs}}).NeedsFullVM() {
+		t.Errorf("Error, expected the full VM to be needed")
+	}
+	if (ListOptions{Fields: []string{"Hostname", "State"}}).NeedsFullVM() {
+		t.Errorf("Error, expected the VM of the list to be enough")
+	}
+}
@@ -1,5 +1,23 @@
 package hosting
 
+import (
+	"strings"
+	"time"
+)
+
+// Status of a Region
+const (
+	// RegionOpen is a Region where resources can be created
+	RegionOpen = "open"
+
+	// RegionClosing is a Region that will be deactivated at
+	// a known date, creating new resources there is discouraged
+	RegionClosing = "closing"
+
+	// RegionClosed is a Region where no resources can be created
+	RegionClosed = "closed"
+)
+
 // RegionManager represents a service capable of getting info
 // about Gandi Datacenters
 type RegionManager interface {
@@ -7,6 +25,10 @@ type RegionManager interface {
 	// Lists every existing Region
 	ListRegions() ([]Region, error)
 
+	// FilterRegions returns the Regions matching `regionfilter`,
+	// e.g. only the open ones
+	FilterRegions(regionfilter RegionFilter) ([]Region, error)
+
 	// Return a Region object given its datacenter code
 	RegionbyCode(code string) (Region, error)
 }
@@ -16,4 +38,55 @@ type Region struct {
 	ID      string
 	Name    string
 	Country string
+
+	// City the datacenter is in
+	City string
+
+	// ISO 3166-1 alpha-2 code of the country, e.g. FR, LU, US
+	ISO string
+
+	// Status of the datacenter: open, closing, closed
+	Status string
+
+	// Date from which the datacenter will not accept new
+	// resources, zero if no deactivation is planned
+	DeactivateAt time.Time
+}
+
+// RegionFilter is used to search Regions, an unset field is ignored
+type RegionFilter struct {
+	// Datacenter code
+	Name string
+
+	// ISO code of the country, compared without case
+	ISO string
+
+	// Status wanted, RegionOpen lists only the Regions
+	// where resources can be created
+	Status string
+
+	// Only list the Regions that will still be active at this date
+	ActiveAt time.Time
+}
+
+// Match tells if `region` matches the filter
+func (f RegionFilter) Match(region Region) bool {
+	if f.Name != "" && f.Name != region.Name {
+		return false
+	}
+	if f.ISO != "" && !strings.EqualFold(f.ISO, region.ISO) {
+		return false
+	}
+	if f.Status != "" && f.Status != region.Status {
+		return false
+	}
+	if !f.ActiveAt.IsZero() {
+		if region.Status == RegionClosed {
+			return false
+		}
+		if !region.DeactivateAt.IsZero() && !f.ActiveAt.Before(region.DeactivateAt) {
+			return false
+		}
+	}
+	return true
 }
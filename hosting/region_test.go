@@ -0,0 +1,27 @@
+package hosting
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegionFilterActiveAt(t *testing.T) {
+	now := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	filter := RegionFilter{ActiveAt: now}
+
+	tests := []struct {
+		region   Region
+		expected bool
+	}{
+		{Region{Status: RegionOpen}, true},
+		{Region{Status: RegionClosed}, false},
+		{Region{Status: RegionClosing, DeactivateAt: now.AddDate(0, 1, 0)}, true},
+		{Region{Status: RegionClosing, DeactivateAt: now}, false},
+	}
+
+	for _, test := range tests {
+		if filter.Match(test.region) != test.expected {
+			t.Errorf("Error, expected %t for %+v", test.expected, test.region)
+		}
+	}
+}
@@ -1,11 +1,27 @@
 package hosting
 
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// ErrKeyNotFound indicates that no SSHKey has the name requested
+var ErrKeyNotFound = errors.New("SSHKey not found")
+
+// ErrKeysUnknown indicates that no VM reports its SSHKeys, they
+// are not known to the driver and cannot be relied upon
+var ErrKeysUnknown = errors.New("SSHKeys of the VMs are not known")
+
 // SSHKeyManager represents a service capable of manipulating
 // SSH Keys in Gandi's platform
 type SSHKeyManager interface {
 
 	// CreateKey creates an SSH Key to use when creating VMs
 	//
+	// `value` must be a valid public key, see ParsePublicKey
 	// Returns an object containing the fingerprint and the ID
 	CreateKey(name string, value string) (SSHKey, error)
 
@@ -13,12 +29,12 @@ type SSHKeyManager interface {
 	DeleteKey(key SSHKey) error
 
 	// KeyFromName return the SSHKey with name `name`,
-	// if no key with such name exists, an empty
-	// object is returned instead
-	KeyFromName(name string) SSHKey
+	// if no key with such name exists, ErrKeyNotFound
+	// is returned
+	KeyFromName(name string) (SSHKey, error)
 
 	// ListKeys lists every SSHKey created
-	ListKeys() []SSHKey
+	ListKeys() ([]SSHKey, error)
 }
 
 // SSHKey represents an ssh key
@@ -28,3 +44,69 @@ type SSHKey struct {
 	Name        string
 	Value       string
 }
+
+// KeyFromNameOrEmpty returns the SSHKey with name `name`, or an
+// empty SSHKey if it does not exist or an error occurred
+//
+// Deprecated: use SSHKeyManager.KeyFromName, which reports errors
+func KeyFromNameOrEmpty(m SSHKeyManager, name string) SSHKey {
+	key, _ := m.KeyFromName(name)
+	return key
+}
+
+// ListKeysOrEmpty lists every SSHKey, or none if an error occurred
+//
+// Deprecated: use SSHKeyManager.ListKeys, which reports errors
+func ListKeysOrEmpty(m SSHKeyManager) []SSHKey {
+	keys, err := m.ListKeys()
+	if err != nil {
+		return []SSHKey{}
+	}
+	return keys
+}
+
+// ImportKeysFromFile ensures every key of an authorized_keys or
+// RFC4716 file exists, see EnsureKey
+//
+// Keys are named after their comment, or after the file and
+// their position in it if they have none
+func ImportKeysFromFile(m SSHKeyManager, path string) ([]SSHKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := ParseAuthorizedKeys(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+
+	var imported []SSHKey
+	for i, key := range keys {
+		name := key.Comment
+		if name == "" {
+			name = fmt.Sprintf("%s-%d", filepath.Base(path), i+1)
+		}
+		sshkey, err := EnsureKey(m, name, key.String())
+		if err != nil {
+			return imported, err
+		}
+		imported = append(imported, sshkey)
+	}
+	return imported, nil
+}
+
+// sameKey tells if `existing` is the same public key as `key`,
+// comparing fingerprints in any of the supported formats
+func sameKey(existing SSHKey, key PublicKey) bool {
+	fingerprint := strings.ToLower(strings.TrimPrefix(existing.Fingerprint, "MD5:"))
+	if fingerprint != "" && fingerprint == key.FingerprintMD5() {
+		return true
+	}
+	if existing.Fingerprint == key.FingerprintSHA256() {
+		return true
+	}
+	if parsed, err := ParsePublicKey(existing.Value); err == nil {
+		return parsed.Type == key.Type && string(parsed.Blob) == string(key.Blob)
+	}
+	return false
+}
@@ -0,0 +1,262 @@
+package hosting
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrBadKey indicates that a public key could not be parsed
+// or that its content does not match its type
+var ErrBadKey = errors.New("Invalid SSH public key")
+
+const (
+	rfc4716Begin = "---- BEGIN SSH2 PUBLIC KEY ----"
+	rfc4716End   = "---- END SSH2 PUBLIC KEY ----"
+)
+
+// Curves of the supported ecdsa keys and their size in bits
+var ecdsaCurves = map[string]int{
+	"nistp256": 256,
+	"nistp384": 384,
+	"nistp521": 521,
+}
+
+// PublicKey is a parsed SSH public key
+type PublicKey struct {
+	// Type of key: ssh-rsa, ssh-ed25519, ecdsa-sha2-nistp256,
+	// ecdsa-sha2-nistp384, ecdsa-sha2-nistp521
+	Type string
+
+	// Size of the key in bits
+	Bits int
+
+	// Comment of the key, usually user@host
+	Comment string
+
+	// Key in SSH wire format
+	Blob []byte
+}
+
+// String returns the key in OpenSSH format, as found
+// in an authorized_keys file
+func (k PublicKey) String() string {
+	s := k.Type + " " + base64.StdEncoding.EncodeToString(k.Blob)
+	if k.Comment != "" {
+		s += " " + k.Comment
+	}
+	return s
+}
+
+// FingerprintMD5 returns the MD5 fingerprint of the key as
+// colon separated hex bytes, the format used by Gandi
+func (k PublicKey) FingerprintMD5() string {
+	sum := md5.Sum(k.Blob)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02x", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+// FingerprintSHA256 returns the SHA256 fingerprint of the key
+// in the format used by OpenSSH, e.g. SHA256:JseExCID...
+func (k PublicKey) FingerprintSHA256() string {
+	sum := sha256.Sum256(k.Blob)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// ParsePublicKey parses a single public key, either in OpenSSH
+// format or in RFC4716 (SSH2) format, and validates its content
+func ParsePublicKey(value string) (PublicKey, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, rfc4716Begin) {
+		return parseRFC4716(value)
+	}
+	return parseAuthorizedKey(value)
+}
+
+// ParseAuthorizedKeys parses the content of an authorized_keys
+// file, one key per line, or of an RFC4716 file
+//
+// Empty lines and comments are ignored, as are the options
+// at the beginning of a line
+func ParseAuthorizedKeys(data []byte) ([]PublicKey, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(rfc4716Begin)) {
+		return parseRFC4716File(string(data))
+	}
+
+	var keys []PublicKey
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := parseAuthorizedKey(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", n, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, scanner.Err()
+}
+
+// parseAuthorizedKey parses a key in OpenSSH format,
+// optionally preceded by authorized_keys options
+func parseAuthorizedKey(line string) (PublicKey, error) {
+	fields := strings.Fields(line)
+	for i := 0; i+1 < len(fields); i++ {
+		if !isKeyType(fields[i]) {
+			// Options, they cannot contain spaces outside quotes
+			// but we only need to find the key type
+			continue
+		}
+		blob, err := base64.StdEncoding.DecodeString(fields[i+1])
+		if err != nil {
+			return PublicKey{}, ErrBadKey
+		}
+		key, err := parseBlob(blob)
+		if err != nil {
+			return PublicKey{}, err
+		}
+		if key.Type != fields[i] {
+			return PublicKey{}, ErrBadKey
+		}
+		key.Comment = strings.Join(fields[i+2:], " ")
+		return key, nil
+	}
+	return PublicKey{}, ErrBadKey
+}
+
+// parseRFC4716File parses every key of an RFC4716 file
+func parseRFC4716File(data string) ([]PublicKey, error) {
+	var keys []PublicKey
+	for {
+		begin := strings.Index(data, rfc4716Begin)
+		if begin < 0 {
+			return keys, nil
+		}
+		end := strings.Index(data, rfc4716End)
+		if end < begin {
+			return nil, ErrBadKey
+		}
+		key, err := parseRFC4716(data[begin : end+len(rfc4716End)])
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		data = data[end+len(rfc4716End):]
+	}
+}
+
+// parseRFC4716 parses a key in RFC4716 format, the Comment
+// header is used as the comment of the key
+func parseRFC4716(value string) (PublicKey, error) {
+	lines := strings.Split(strings.Replace(value, "\r\n", "\n", -1), "\n")
+	if len(lines) < 3 || strings.TrimSpace(lines[len(lines)-1]) != rfc4716End {
+		return PublicKey{}, ErrBadKey
+	}
+	lines = lines[1 : len(lines)-1]
+
+	var comment string
+	var body strings.Builder
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if !strings.Contains(line, ":") {
+			body.WriteString(line)
+			continue
+		}
+		// Header, a trailing backslash continues it on the next line
+		header := line
+		for strings.HasSuffix(header, "\\") && i+1 < len(lines) {
+			i++
+			header = strings.TrimSuffix(header, "\\") + strings.TrimSpace(lines[i])
+		}
+		parts := strings.SplitN(header, ":", 2)
+		if strings.EqualFold(parts[0], "Comment") {
+			comment = strings.Trim(strings.TrimSpace(parts[1]), "\"")
+		}
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(body.String())
+	if err != nil {
+		return PublicKey{}, ErrBadKey
+	}
+	key, err := parseBlob(blob)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	key.Comment = comment
+	return key, nil
+}
+
+// parseBlob validates a key in wire format and returns its type and size
+func parseBlob(blob []byte) (PublicKey, error) {
+	keytype, rest, ok := readString(blob)
+	if !ok {
+		return PublicKey{}, ErrBadKey
+	}
+	key := PublicKey{Type: string(keytype), Blob: blob}
+
+	switch {
+	case key.Type == "ssh-rsa":
+		e, rest, ok := readString(rest)
+		if !ok || len(e) == 0 {
+			return PublicKey{}, ErrBadKey
+		}
+		n, rest, ok := readString(rest)
+		if !ok || len(rest) != 0 {
+			return PublicKey{}, ErrBadKey
+		}
+		key.Bits = new(big.Int).SetBytes(n).BitLen()
+		if key.Bits < 1024 {
+			return PublicKey{}, ErrBadKey
+		}
+	case key.Type == "ssh-ed25519":
+		point, rest, ok := readString(rest)
+		if !ok || len(point) != 32 || len(rest) != 0 {
+			return PublicKey{}, ErrBadKey
+		}
+		key.Bits = 256
+	case strings.HasPrefix(key.Type, "ecdsa-sha2-"):
+		curve, rest, ok := readString(rest)
+		bits, known := ecdsaCurves[string(curve)]
+		if !ok || !known || key.Type != "ecdsa-sha2-"+string(curve) {
+			return PublicKey{}, ErrBadKey
+		}
+		// Uncompressed point: 0x04 followed by both coordinates
+		point, rest, ok := readString(rest)
+		size := (bits + 7) / 8
+		if !ok || len(point) != 1+2*size || point[0] != 4 || len(rest) != 0 {
+			return PublicKey{}, ErrBadKey
+		}
+		key.Bits = bits
+	default:
+		return PublicKey{}, ErrBadKey
+	}
+	return key, nil
+}
+
+// readString reads a length prefixed string of the SSH wire format
+func readString(data []byte) ([]byte, []byte, bool) {
+	if len(data) < 4 {
+		return nil, nil, false
+	}
+	length := binary.BigEndian.Uint32(data)
+	if uint64(length) > uint64(len(data)-4) {
+		return nil, nil, false
+	}
+	return data[4 : 4+length], data[4+length:], true
+}
+
+func isKeyType(s string) bool {
+	return s == "ssh-rsa" || s == "ssh-ed25519" || strings.HasPrefix(s, "ecdsa-sha2-")
+}
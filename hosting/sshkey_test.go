@@ -0,0 +1,178 @@
+package hosting
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+var (
+	ed25519Key = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIIwU1b1hHgqAHzuJswCM0MjjoMLfWZPesjZ58T+e5XGB test@hosting"
+	rsaKey     = "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQCzi448NY1VfOyTcHszheIltmZ7EB3qoW1CRm8CoWsxBWYnYKwwACDRI/6g8OYWs83w+veRVrOhj84+EsHHNT64+fym8fiyfY8thy/NvKlqaIucQh/UF8pEC4+VgCqyjlxFiC9OOhFASwz0GizeC06XLUiB6WMpmbOz7qRF6fQLCw== rsa@hosting"
+	ecdsaKey   = "ecdsa-sha2-nistp256 AAAAE2VjZHNhLXNoYTItbmlzdHAyNTYAAAAIbmlzdHAyNTYAAABBBF80hyngISAfcLCzwjfZkelmyWsVSdkizpCkM43PdnCX/+mj3xgZT5BmRi2t//IyzAG2GqSdO2ia+kdmWXsOmV4= ecdsa@hosting"
+	rsaRFC4716 = `---- BEGIN SSH2 PUBLIC KEY ----
+Comment: "1024-bit RSA, converted by \
+root@vm from OpenSSH"
+AAAAB3NzaC1yc2EAAAADAQABAAAAgQCzi448NY1VfOyTcHszheIltmZ7EB3qoW1CRm8CoW
+sxBWYnYKwwACDRI/6g8OYWs83w+veRVrOhj84+EsHHNT64+fym8fiyfY8thy/NvKlqaIuc
+Qh/UF8pEC4+VgCqyjlxFiC9OOhFASwz0GizeC06XLUiB6WMpmbOz7qRF6fQLCw==
+---- END SSH2 PUBLIC KEY ----`
+)
+
+func TestParsePublicKey(t *testing.T) {
+	// Fingerprints given by ssh-keygen -l
+	tests := []struct {
+		value  string
+		typ    string
+		bits   int
+		md5    string
+		sha256 string
+	}{
+		{ed25519Key, "ssh-ed25519", 256,
+			"ff:86:a8:ff:42:7d:4c:99:60:d0:da:9c:c1:23:b4:5d",
+			"SHA256:JseExCIDVruWgnV4Ov9o8dspkxLYTZOxkSAs6KhIdqY"},
+		{rsaKey, "ssh-rsa", 1024,
+			"9e:b0:a9:c0:8f:e2:8c:73:50:79:0e:bd:ac:5a:12:94",
+			"SHA256:t+Gayy6Aa9SHvsdiGA4XlqJyHWOvvimxDa1fAoaaUSY"},
+		{ecdsaKey, "ecdsa-sha2-nistp256", 256,
+			"21:7e:61:b5:58:fa:ca:92:4b:dc:27:f7:88:ca:b6:3c",
+			"SHA256:q5IE624k9tPwSOVJawlFC+NVQZgvH7VZ6yHRZXQYXtA"},
+		{rsaRFC4716, "ssh-rsa", 1024,
+			"9e:b0:a9:c0:8f:e2:8c:73:50:79:0e:bd:ac:5a:12:94",
+			"SHA256:t+Gayy6Aa9SHvsdiGA4XlqJyHWOvvimxDa1fAoaaUSY"},
+	}
+
+	for _, test := range tests {
+		key, err := ParsePublicKey(test.value)
+		if err != nil {
+			t.Errorf("Error, %s parsing %s", err, test.value)
+			continue
+		}
+		if key.Type != test.typ || key.Bits != test.bits {
+			t.Errorf("Error, expected %s %d, got instead %s %d", test.typ, test.bits, key.Type, key.Bits)
+		}
+		if key.FingerprintMD5() != test.md5 {
+			t.Errorf("Error, expected %s, got instead %s", test.md5, key.FingerprintMD5())
+		}
+		if key.FingerprintSHA256() != test.sha256 {
+			t.Errorf("Error, expected %s, got instead %s", test.sha256, key.FingerprintSHA256())
+		}
+	}
+
+	key, _ := ParsePublicKey(rsaRFC4716)
+	if key.Comment != "1024-bit RSA, converted by root@vm from OpenSSH" {
+		t.Errorf("Error, unexpected comment '%s'", key.Comment)
+	}
+}
+
+func TestParsePublicKeyInvalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"ssh-rsa 12345 test@hosting",
+		// ed25519 blob declared as rsa
+		"ssh-rsa AAAAC3NzaC1lZDI1NTE5AAAAIIwU1b1hHgqAHzuJswCM0MjjoMLfWZPesjZ58T+e5XGB",
+		// truncated ed25519 blob
+		"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIIwU1b1hHgqAHzuJswCM0MjjoMLfWZPesjZ58T+e",
+		"ssh-dss AAAAB3NzaC1kc3MAAACBAP",
+	}
+	for _, value := range invalid {
+		if _, err := ParsePublicKey(value); err == nil {
+			t.Errorf("Error, expected error parsing '%s'", value)
+		}
+	}
+}
+
+func TestParseAuthorizedKeys(t *testing.T) {
+	data := "# deploy keys\n\n" +
+		ed25519Key + "\n" +
+		`no-pty,command="echo hello" ` + rsaKey + "\n"
+
+	keys, err := ParseAuthorizedKeys([]byte(data))
+	if err != nil {
+		t.Fatalf("Error, %s", err)
+	}
+	if len(keys) != 2 || keys[1].Type != "ssh-rsa" || keys[1].Comment != "rsa@hosting" {
+		t.Errorf("Error, unexpected keys %+v", keys)
+	}
+	if keys[0].String() != ed25519Key {
+		t.Errorf("Error, expected %s, got instead %s", ed25519Key, keys[0].String())
+	}
+
+	_, err = ParseAuthorizedKeys([]byte(ed25519Key + "\nnot a key\n"))
+	if err == nil {
+		t.Errorf("Error, expected error for an invalid line")
+	}
+}
+
+// fakeKeyManager stores keys in memory
+type fakeKeyManager struct {
+	SSHKeyManager
+	keys []SSHKey
+}
+
+func (m *fakeKeyManager) ListKeys() ([]SSHKey, error) {
+	return m.keys, nil
+}
+
+func (m *fakeKeyManager) KeyFromName(name string) (SSHKey, error) {
+	for _, key := range m.keys {
+		if key.Name == name {
+			return key, nil
+		}
+	}
+	return SSHKey{}, ErrKeyNotFound
+}
+
+func (m *fakeKeyManager) CreateKey(name, value string) (SSHKey, error) {
+	key := SSHKey{Name: name, Value: value}
+	m.keys = append(m.keys, key)
+	return key, nil
+}
+
+func TestEnsureKey(t *testing.T) {
+	m := &fakeKeyManager{keys: []SSHKey{
+		{Name: "old", Fingerprint: "FF:86:A8:FF:42:7D:4C:99:60:D0:DA:9C:C1:23:B4:5D"},
+	}}
+
+	key, err := EnsureKey(m, "new", ed25519Key)
+	if err != nil || key.Name != "old" {
+		t.Errorf("Error, expected the existing key, got instead %+v (%v)", key, err)
+	}
+
+	key, err = EnsureKey(m, "rsa", rsaKey)
+	if err != nil || key.Name != "rsa" || len(m.keys) != 2 {
+		t.Errorf("Error, expected a new key, got instead %+v (%v)", key, err)
+	}
+
+	if _, err := EnsureKey(m, "bad", "ssh-rsa 12345"); err == nil {
+		t.Errorf("Error, expected error for an invalid key")
+	}
+
+	if _, err := EnsureKey(m, "rsa", ecdsaKey); err == nil {
+		t.Errorf("Error, expected error for a key with the same name and another value")
+	}
+}
+
+func TestImportKeysFromFile(t *testing.T) {
+	file, err := ioutil.TempFile("", "authorized_keys")
+	if err != nil {
+		t.Fatalf("Error, %s", err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString(ed25519Key + "\n" + ecdsaKey[:len(ecdsaKey)-len(" ecdsa@hosting")] + "\n" + ed25519Key + "\n")
+	file.Close()
+
+	m := &fakeKeyManager{}
+	keys, err := ImportKeysFromFile(m, file.Name())
+	if err != nil {
+		t.Fatalf("Error, %s", err)
+	}
+
+	// The duplicated key is only created once
+	if len(keys) != 3 || len(m.keys) != 2 {
+		t.Fatalf("Error, expected 2 keys created, got instead %+v", m.keys)
+	}
+	if m.keys[0].Name != "test@hosting" || m.keys[1].Name == "" {
+		t.Errorf("Error, unexpected names in %+v", m.keys)
+	}
+}
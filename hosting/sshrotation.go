@@ -0,0 +1,109 @@
+package hosting
+
+import "errors"
+
+// Outcomes of the rotation of an SSHKey on a VM
+const (
+	RotationDone    = "rotated"
+	RotationSkipped = "skipped"
+	RotationFailed  = "failed"
+)
+
+// SSHKeyRotation is the outcome of an SSHKey rotation on a VM
+type SSHKeyRotation struct {
+	// VM concerned, updated if the rotation was done
+	VM VM
+
+	// Outcome: rotated, skipped if the VM did not have
+	// the old key, or failed
+	Status string
+
+	// Error returned by the failed operation
+	Err error
+}
+
+// RotateSSHKey replaces `oldKey` by `newKey` on every VM matching
+// `vmfilter` that has `oldKey`
+//
+// The new key is added before the old one is removed so that access
+// to a VM is never lost. A failure on a VM does not stop the rotation
+// on the others, the outcome of each VM is reported in order
+//
+// VMs only know the names of their keys, `oldKey` given by ID is
+// looked up first, ErrKeyNotFound is returned if it does not exist.
+// If none of the VMs reports a key, ErrKeysUnknown is returned
+// instead of skipping every VM. FieldSSHKeys is added to the
+// fields of `vmfilter` when they are restricted, see ListOptions
+func RotateSSHKey(h Hosting, vmfilter VMFilter, oldKey, newKey SSHKey) ([]SSHKeyRotation, error) {
+	oldKey, err := resolveKey(h, oldKey)
+	if err != nil {
+		return nil, err
+	}
+	if fields := vmfilter.Options.Fields; len(fields) > 0 {
+		vmfilter.Options.Fields = append(append([]string{}, fields...), FieldSSHKeys)
+	}
+	vms, err := h.ListVMs(vmfilter)
+	if err != nil {
+		return nil, err
+	}
+	if len(vms) > 0 && !anyKey(vms) {
+		return nil, ErrKeysUnknown
+	}
+
+	var rotations []SSHKeyRotation
+	for _, vm := range vms {
+		if !hasKey(vm, oldKey) {
+			rotations = append(rotations, SSHKeyRotation{vm, RotationSkipped, nil})
+			continue
+		}
+		updated, err := h.AddSSHKeyToVM(vm, newKey)
+		if err == nil {
+			updated, err = h.RemoveSSHKeyFromVM(updated, oldKey)
+		}
+		if err != nil {
+			rotations = append(rotations, SSHKeyRotation{vm, RotationFailed, err})
+			continue
+		}
+		rotations = append(rotations, SSHKeyRotation{updated, RotationDone, nil})
+	}
+	return rotations, nil
+}
+
+// resolveKey returns `key` with its name, found from its ID if needed
+func resolveKey(m SSHKeyManager, key SSHKey) (SSHKey, error) {
+	if key.Name != "" {
+		return key, nil
+	}
+	if key.ID == "" {
+		return SSHKey{}, errors.New("SSHKey to rotate has no name nor ID")
+	}
+	keys, err := m.ListKeys()
+	if err != nil {
+		return SSHKey{}, err
+	}
+	for _, k := range keys {
+		if k.ID == key.ID {
+			return k, nil
+		}
+	}
+	return SSHKey{}, ErrKeyNotFound
+}
+
+func hasKey(vm VM, key SSHKey) bool {
+	for _, name := range vm.SSHKeys {
+		if name == key.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// anyKey reports whether one of `vms` has an SSHKey
+func anyKey(vms []VM) bool {
+	for _, vm := range vms {
+		if len(vm.SSHKeys) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package hosting
+
+import (
+	"errors"
+	"testing"
+)
+
+// rotationHosting keeps the keys of its VMs in memory
+type rotationHosting struct {
+	Hosting
+	vms    []VM
+	failOn string
+}
+
+func (h *rotationHosting) ListVMs(vmfilter VMFilter) ([]VM, error) {
+	// Like ListVMs of the v4 driver, keys are only read
+	// from the full VMs
+	if !vmfilter.Options.NeedsFullVM() {
+		var vms []VM
+		for _, vm := range h.vms {
+			vm.SSHKeys = nil
+			vms = append(vms, vm)
+		}
+		return vms, nil
+	}
+	return h.vms, nil
+}
+
+func (h *rotationHosting) ListKeys() ([]SSHKey, error) {
+	return []SSHKey{{ID: "7", Name: "old"}, {ID: "8", Name: "admin"}}, nil
+}
+
+func (h *rotationHosting) AddSSHKeyToVM(vm VM, key SSHKey) (VM, error) {
+	if vm.ID == h.failOn {
+		return VM{}, errors.New("failed")
+	}
+	vm.SSHKeys = append(append([]string{}, vm.SSHKeys...), key.Name)
+	return vm, nil
+}
+
+func (h *rotationHosting) RemoveSSHKeyFromVM(vm VM, key SSHKey) (VM, error) {
+	var keys []string
+	for _, name := range vm.SSHKeys {
+		if name != key.Name {
+			keys = append(keys, name)
+		}
+	}
+	vm.SSHKeys = keys
+	return vm, nil
+}
+
+func TestRotateSSHKey(t *testing.T) {
+	h := &rotationHosting{
+		vms: []VM{
+			{ID: "1", SSHKeys: []string{"old", "admin"}},
+			{ID: "2", SSHKeys: []string{"admin"}},
+			{ID: "3", SSHKeys: []string{"old"}},
+		},
+		failOn: "3",
+	}
+
+	rotations, err := RotateSSHKey(h, VMFilter{Farm: "web"}, SSHKey{Name: "old"}, SSHKey{Name: "new"})
+	if err != nil {
+		t.Fatalf("Error, %s", err)
+	}
+
+	expected := []string{RotationDone, RotationSkipped, RotationFailed}
+	for i, rotation := range rotations {
+		if rotation.Status != expected[i] {
+			t.Errorf("Error, expected %s for VM %s, got instead %+v", expected[i], rotation.VM.ID, rotation)
+		}
+	}
+	if keys := rotations[0].VM.SSHKeys; len(keys) != 2 || keys[0] != "admin" || keys[1] != "new" {
+		t.Errorf("Error, unexpected keys %v", keys)
+	}
+	if rotations[2].Err == nil {
+		t.Errorf("Error, expected the error of the failed rotation")
+	}
+}
+
+func TestRotateSSHKeyByID(t *testing.T) {
+	h := &rotationHosting{vms: []VM{{ID: "1", SSHKeys: []string{"old"}}}}
+
+	rotations, err := RotateSSHKey(h, VMFilter{}, SSHKey{ID: "7"}, SSHKey{Name: "new"})
+	if err != nil || len(rotations) != 1 || rotations[0].Status != RotationDone {
+		t.Errorf("Error, expected the key to be rotated, got instead %+v (%v)", rotations, err)
+	}
+
+	_, err = RotateSSHKey(h, VMFilter{}, SSHKey{ID: "9"}, SSHKey{Name: "new"})
+	if err != ErrKeyNotFound {
+		t.Errorf("Error, expected %v, got instead %v", ErrKeyNotFound, err)
+	}
+}
+
+func TestRotateSSHKeyUnknownKeys(t *testing.T) {
+	h := &rotationHosting{vms: []VM{{ID: "1"}, {ID: "2"}}}
+
+	rotations, err := RotateSSHKey(h, VMFilter{}, SSHKey{Name: "old"}, SSHKey{Name: "new"})
+	if err != ErrKeysUnknown || len(rotations) > 0 {
+		t.Errorf("Error, expected %v, got instead %+v (%v)", ErrKeysUnknown, rotations, err)
+	}
+}
+
+func TestRotateSSHKeyWithFields(t *testing.T) {
+	h := &rotationHosting{vms: []VM{{ID: "1", SSHKeys: []string{"old"}}}}
+
+	fields := VMFilter{Options: ListOptions{Fields: []string{"Hostname"}}}
+	rotations, err := RotateSSHKey(h, fields, SSHKey{Name: "old"}, SSHKey{Name: "new"})
+	if err != nil || len(rotations) != 1 || rotations[0].Status != RotationDone {
+		t.Errorf("Error, expected the key to be rotated, got instead %+v (%v)", rotations, err)
+	}
+	if len(fields.Options.Fields) != 1 {
+		t.Errorf("Error, expected the fields of the caller to be kept, got %v", fields.Options.Fields)
+	}
+}
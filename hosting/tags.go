@@ -0,0 +1,209 @@
+package hosting
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FarmTag is the tag holding the Farm of a VM
+//
+// It cannot be set with TagVM, the Farm of a VM
+// is given on creation, see VMSpec
+const FarmTag = "farm"
+
+const (
+	// Line of a VM description holding its tags
+	tagsLinePrefix = "tags:"
+
+	// Separators of the tags in the name of Disks and Vlans
+	tagsNameSeparator  = "--"
+	tagsValueSeparator = "_"
+)
+
+// Tags are key/value labels on resources
+//
+// The v4 API has no tags, they are emulated: the tags of a VM are
+// kept in its description and its Farm is the tag FarmTag, the
+// tags of Disks and Vlans are part of their name, e.g. a Disk named
+// data--env_prod--team_web has tags env=prod and team=web. IPs have
+// the tags of the VM they are attached to, or of their Vlan for
+// private IPs
+//
+// Keys and values of tags encoded in names can only contain
+// letters and digits
+type Tags map[string]string
+
+// Match reports whether the tags contain every tag of `filter`,
+// an empty value in `filter` matches any value
+func (t Tags) Match(filter Tags) bool {
+	for key, value := range filter {
+		v, ok := t[key]
+		if !ok || (value != "" && v != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the tags sorted by key, e.g. env=prod team=web
+func (t Tags) String() string {
+	keys := make([]string, 0, len(t))
+	for key := range t {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + "=" + t[key]
+	}
+	return strings.Join(parts, " ")
+}
+
+// VMTags returns the tags of a VM, read from its description and Farm
+func VMTags(vm VM) Tags {
+	tags := Tags{}
+	for _, line := range strings.Split(vm.Description, "\n") {
+		if !strings.HasPrefix(line, tagsLinePrefix) {
+			continue
+		}
+		for _, field := range strings.Fields(strings.TrimPrefix(line, tagsLinePrefix)) {
+			parts := strings.SplitN(field, "=", 2)
+			if len(parts) == 2 {
+				tags[parts[0]] = parts[1]
+			}
+		}
+	}
+	if vm.Farm != "" {
+		tags[FarmTag] = vm.Farm
+	}
+	return tags
+}
+
+// DescriptionWithTags returns `description` with its tags
+// replaced by `tags`, only the line of the tags is changed,
+// the rest of the description is kept as it is
+func DescriptionWithTags(description string, tags Tags) (string, error) {
+	for key, value := range tags {
+		if key == "" || strings.ContainsAny(key, "= \n") || strings.ContainsAny(value, " \n") {
+			return "", fmt.Errorf("Invalid tag '%s=%s'", key, value)
+		}
+	}
+	var lines []string
+	for _, line := range strings.Split(description, "\n") {
+		if !strings.HasPrefix(line, tagsLinePrefix) {
+			lines = append(lines, line)
+		}
+	}
+	description = strings.Join(lines, "\n")
+	if len(tags) == 0 {
+		return description, nil
+	}
+	if description != "" {
+		description += "\n"
+	}
+	return description + tagsLinePrefix + " " + tags.String(), nil
+}
+
+// TagVM replaces the tags of a VM by `tags`
+func TagVM(m VMManager, vm VM, tags Tags) (VM, error) {
+	if _, ok := tags[FarmTag]; ok {
+		return VM{}, fmt.Errorf("Tag '%s' is the Farm of the VM and cannot be set", FarmTag)
+	}
+	description, err := DescriptionWithTags(vm.Description, tags)
+	if err != nil {
+		return VM{}, err
+	}
+	return m.UpdateVMDescription(vm, description)
+}
+
+// NameWithTags returns the name of a Disk or a Vlan named
+// `base` with `tags`, `base` cannot contain "--"
+func NameWithTags(base string, tags Tags) (string, error) {
+	if base == "" || strings.Contains(base, tagsNameSeparator) {
+		return "", fmt.Errorf("Invalid name '%s' for tags", base)
+	}
+	name := base
+	for _, tag := range strings.Fields(tags.String()) {
+		parts := strings.SplitN(tag, "=", 2)
+		if !isAlphanumeric(parts[0]) || !isAlphanumeric(parts[1]) {
+			return "", fmt.Errorf("Invalid tag '%s', only letters and digits can be used in names", tag)
+		}
+		name += tagsNameSeparator + parts[0] + tagsValueSeparator + parts[1]
+	}
+	return name, nil
+}
+
+// ParseTaggedName splits the name of a Disk or a Vlan
+// into its base name and its tags
+func ParseTaggedName(name string) (string, Tags) {
+	parts := strings.Split(name, tagsNameSeparator)
+	tags := Tags{}
+	for _, part := range parts[1:] {
+		kv := strings.SplitN(part, tagsValueSeparator, 2)
+		if len(kv) == 2 {
+			tags[kv[0]] = kv[1]
+		}
+	}
+	return parts[0], tags
+}
+
+// DiskTags returns the tags of a Disk, read from its name
+func DiskTags(disk Disk) Tags {
+	_, tags := ParseTaggedName(disk.Name)
+	return tags
+}
+
+// VlanTags returns the tags of a Vlan, read from its name
+func VlanTags(vlan Vlan) Tags {
+	_, tags := ParseTaggedName(vlan.Name)
+	return tags
+}
+
+// IPTags returns the tags of an IP: the tags of the VM it is
+// attached to, found in `vms`, or of its Vlan, found in `vlans`
+func IPTags(ip IPAddress, vms []VM, vlans []Vlan) Tags {
+	for _, vm := range vms {
+		if ip.VM != "" && vm.ID == ip.VM {
+			return VMTags(vm)
+		}
+	}
+	for _, vlan := range vlans {
+		if ip.Vlan != "" && vlan.ID == ip.Vlan {
+			return VlanTags(vlan)
+		}
+	}
+	return Tags{}
+}
+
+// TagDisk replaces the tags of a Disk by `tags`, renaming it
+func TagDisk(m DiskManager, disk Disk, tags Tags) (Disk, error) {
+	base, _ := ParseTaggedName(disk.Name)
+	name, err := NameWithTags(base, tags)
+	if err != nil {
+		return Disk{}, err
+	}
+	return m.RenameDisk(disk, name)
+}
+
+// TagVlan replaces the tags of a Vlan by `tags`, renaming it
+func TagVlan(m VlanManager, vlan Vlan, tags Tags) (Vlan, error) {
+	base, _ := ParseTaggedName(vlan.Name)
+	name, err := NameWithTags(base, tags)
+	if err != nil {
+		return Vlan{}, err
+	}
+	return m.RenameVlan(vlan, name)
+}
+
+func isAlphanumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package hosting
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDescriptionWithTags(t *testing.T) {
+	description, err := DescriptionWithTags("Web server\ntags: env=dev", Tags{"team": "web", "env": "prod"})
+	if err != nil {
+		t.Fatalf("Error, %s", err)
+	}
+	if description != "Web server\ntags: env=prod team=web" {
+		t.Errorf("Error, unexpected description %q", description)
+	}
+
+	tags := VMTags(VM{Description: description, Farm: "front"})
+	expected := Tags{"env": "prod", "team": "web", FarmTag: "front"}
+	if !reflect.DeepEqual(expected, tags) {
+		t.Errorf("Error, expected %v, got instead %v", expected, tags)
+	}
+
+	description, _ = DescriptionWithTags(description, nil)
+	if description != "Web server" {
+		t.Errorf("Error, expected the tags to be removed, got instead %q", description)
+	}
+
+	// Blank lines are kept
+	description, _ = DescriptionWithTags("Web server\n\n  Debian\n", Tags{"env": "prod"})
+	if description != "Web server\n\n  Debian\n\ntags: env=prod" {
+		t.Errorf("Error, unexpected description %q", description)
+	}
+	description, _ = DescriptionWithTags(description, nil)
+	if description != "Web server\n\n  Debian\n" {
+		t.Errorf("Error, expected the description to be kept, got instead %q", description)
+	}
+
+	if _, err := DescriptionWithTags("", Tags{"owner": "John Doe"}); err == nil {
+		t.Errorf("Error, expected error for a value with a space")
+	}
+}
+
+func TestNameWithTags(t *testing.T) {
+	name, err := NameWithTags("web-data", Tags{"team": "web", "env": "prod"})
+	if err != nil || name != "web-data--env_prod--team_web" {
+		t.Errorf("Error, unexpected name %s (%v)", name, err)
+	}
+
+	base, tags := ParseTaggedName(name)
+	if base != "web-data" || !reflect.DeepEqual(Tags{"env": "prod", "team": "web"}, tags) {
+		t.Errorf("Error, unexpected base %s and tags %v", base, tags)
+	}
+
+	if _, err := NameWithTags("data", Tags{"env": "pre-prod"}); err == nil {
+		t.Errorf("Error, expected error for a value with a dash")
+	}
+	if _, err := NameWithTags("web--data", nil); err == nil {
+		t.Errorf("Error, expected error for a name with the separator")
+	}
+}
+
+func TestTagsMatch(t *testing.T) {
+	tags := Tags{"env": "prod", "team": "web"}
+	filters := []struct {
+		filter   Tags
+		expected bool
+	}{
+		{nil, true},
+		{Tags{"env": "prod"}, true},
+		{Tags{"team": ""}, true},
+		{Tags{"env": "dev"}, false},
+		{Tags{"owner": ""}, false},
+	}
+	for _, test := range filters {
+		if tags.Match(test.filter) != test.expected {
+			t.Errorf("Error, expected %t for %v", test.expected, test.filter)
+		}
+	}
+}
+
+func TestIPTags(t *testing.T) {
+	vms := []VM{{ID: "1", Description: "tags: env=prod"}}
+	vlans := []Vlan{{ID: "2", Name: "lan--env_dev"}}
+
+	if tags := IPTags(IPAddress{VM: "1"}, vms, vlans); tags["env"] != "prod" {
+		t.Errorf("Error, expected the tags of the VM, got instead %v", tags)
+	}
+	if tags := IPTags(IPAddress{Vlan: "2"}, vms, vlans); tags["env"] != "dev" {
+		t.Errorf("Error, expected the tags of the Vlan, got instead %v", tags)
+	}
+}
+
+// renameHosting records the new names given
+type renameHosting struct {
+	Hosting
+}
+
+func (h renameHosting) RenameDisk(disk Disk, name string) (Disk, error) {
+	disk.Name = name
+	return disk, nil
+}
+
+func TestTagDisk(t *testing.T) {
+	disk, err := TagDisk(renameHosting{}, Disk{Name: "data--env_dev"}, Tags{"env": "prod"})
+	if err != nil || disk.Name != "data--env_prod" {
+		t.Errorf("Error, unexpected Disk %+v (%v)", disk, err)
+	}
+	if _, err := TagVM(renameHosting{}, VM{}, Tags{FarmTag: "web"}); err == nil {
+		t.Errorf("Error, expected error setting the farm")
+	}
+}
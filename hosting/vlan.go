@@ -1,5 +1,10 @@
 package hosting
 
+import "errors"
+
+// ErrVlanNotFound indicates that no Vlan has the name requested
+var ErrVlanNotFound = errors.New("Vlan not found")
+
 // VlanManager represents a service capable of manipulating
 // private networks within Gandi's platform
 type VlanManager interface {
@@ -11,10 +16,20 @@ type VlanManager interface {
 	// An unset field in `vlanfilter` is ignored when making the
 	// request
 	ListVlans(vlanfilter VlanFilter) ([]Vlan, error)
+
+	// VlanFromName returns the Vlan with name `name`, if
+	// it does not exist ErrVlanNotFound is returned
 	VlanFromName(name string) (Vlan, error)
 	UpdateVlanGW(vlan Vlan, newGW string) (Vlan, error)
 	RenameVlan(vlan Vlan, newName string) (Vlan, error)
 	DeleteVlan(vlan Vlan) error
+
+	// ListVlanMembers returns the VMs and the private IPs
+	// connected to a Vlan
+	//
+	// A Vlan can only be deleted once it has no members left,
+	// see DestroyVlan
+	ListVlanMembers(vlan Vlan) (VlanMembers, error)
 }
 
 // Vlan represents a private network
@@ -26,6 +41,16 @@ type Vlan struct {
 	RegionID string
 }
 
+// VlanMembers contains the objects connected to a Vlan
+type VlanMembers struct {
+	// VMs with at least one private IP in the Vlan
+	VMs []VM
+
+	// Private IPs of the Vlan, attached to
+	// a VM or not
+	IPs []IPAddress
+}
+
 // VlanSpec contains the information needed
 // to create a private network
 type VlanSpec struct {
@@ -37,6 +62,8 @@ type VlanSpec struct {
 
 // VlanFilter is a struct to define filtering criteria
 // when listing Vlan objects
+//
+// `Name` can be a pattern, see MatchName
 type VlanFilter struct {
 	ID       []string
 	RegionID []string
@@ -1,6 +1,13 @@
 package hosting
 
-import "time"
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrVMNotFound indicates that no VM has the name requested
+var ErrVMNotFound = errors.New("VM not found")
 
 // VMManager represents a service capable of manipulation virtual machine objects in Gandi's platform
 type VMManager interface {
@@ -50,6 +57,15 @@ type VMManager interface {
 	// DetachIP detaches an IPAddress from a VM
 	DetachIP(vm VM, ip IPAddress) (VM, IPAddress, error)
 
+	// MoveIP detaches an IPAddress from VM `from` and attaches it
+	// to VM `to` as a single operation
+	//
+	// If the attachment fails, the IPAddress is attached back
+	// to `from`. The state of both VMs can optionally be checked
+	// before doing anything, see MoveIPOptions
+	// It returns the updated `from` and `to` VMs and the IPAddress
+	MoveIP(ip IPAddress, from VM, to VM, opts MoveIPOptions) (VM, VM, IPAddress, error)
+
 	// Operations on VM state
 	StartVM(vm VM) error
 	StopVM(vm VM) error
@@ -60,12 +76,13 @@ type VMManager interface {
 	// To be able to delete a VM it is first needed to call StopVM
 	// The first IP and the Disk used as boot disk are deleted with
 	// the VM if they are not detached after stopping the VM
+	// See DestroyVM to choose what happens to them
 	DeleteVM(vm VM) error
 
 	// VMFromName returns a VM given a name
 	//
 	// If a VM with name provided does not exist,
-	// an error is returned
+	// ErrVMNotFound is returned
 	VMFromName(name string) (VM, error)
 
 	// ListVMs return a list of VMs, filtered with the options
@@ -90,6 +107,29 @@ type VMManager interface {
 
 	// RenameVM renames a VM
 	RenameVM(vm VM, newname string) (VM, error)
+
+	// UpdateVMDescription replaces the description of a VM,
+	// see TagVM to keep tags in it
+	UpdateVMDescription(vm VM, description string) (VM, error)
+
+	// EnableConsole enables the emergency web console of a VM
+	//
+	// The URL to access the console is returned in the
+	// `Console` field of the updated VM
+	EnableConsole(vm VM) (VM, error)
+
+	// DisableConsole disables the emergency web console of a VM
+	DisableConsole(vm VM) (VM, error)
+
+	// AddSSHKeyToVM adds an SSHKey to the keys of a VM
+	//
+	// Nothing is done if the VM already has the key
+	AddSSHKeyToVM(vm VM, key SSHKey) (VM, error)
+
+	// RemoveSSHKeyFromVM removes an SSHKey from the keys of a VM
+	//
+	// Nothing is done if the VM does not have the key
+	RemoveSSHKeyFromVM(vm VM, key SSHKey) (VM, error)
 }
 
 // VM represents a virtual machine
@@ -107,9 +147,8 @@ type VM struct {
 	// Farm tag
 	Farm string
 
-	// We actually forgot about
-	// this one during the creation
-	// so it will be empty
+	// Description of the VM, it holds the tags read
+	// by VMTags, see DescriptionWithTags
 	Description string
 
 	// Number of cores
@@ -135,6 +174,19 @@ type VM struct {
 	// paused, running, halted, locked,
 	// being_created, deleted, being_migrated
 	State string
+
+	// Emergency console access of the VM
+	Console ConsoleInfo
+}
+
+// ConsoleInfo describes the emergency web console of a VM
+type ConsoleInfo struct {
+	// Set to true if the console is enabled
+	Enabled bool
+
+	// URL to connect to the console,
+	// only set when the console is enabled
+	URL string
 }
 
 // VMSpec contains the parameters
@@ -153,12 +205,21 @@ type VMSpec struct {
 	// It's just a tag...
 	Farm string
 
+	// Optional description of the VM, see
+	// DescriptionWithTags to add tags to it
+	Description string
+
 	// Memory in MB
 	Memory int
 
 	// Number of cores
 	Cores int
 
+	// Bandwidth in kbps of the IP created
+	// with the VM, DefaultBandwidth is used
+	// if not provided
+	Bandwidth float32
+
 	// List of SSHKey names to be copied
 	// inside the VM on creation
 	SSHKeysID []string
@@ -167,14 +228,79 @@ type VMSpec struct {
 	// into the VM
 	Login    string
 	Password string
+
+	// CheckQuota makes the creation functions call
+	// AccountManager.CheckQuota before creating anything
+	CheckQuota bool
+
+	// Rollback makes CreateVM and CreateVMWithExistingIP delete
+	// the VM, Disk and IP they created if a step of the creation
+	// fails, a *RollbackError is then returned
+	Rollback bool
+}
+
+// RollbackError is returned by a creation that failed
+// once resources were created, when VMSpec.Rollback is set
+type RollbackError struct {
+	// Error that made the creation fail
+	Err error
+
+	// Resources deleted by the rollback, e.g. "Disk 12"
+	Deleted []string
+
+	// Resources created that could not be deleted
+	// and are left in the account
+	Left []string
+}
+
+func (e *RollbackError) Error() string {
+	msg := e.Err.Error()
+	if len(e.Deleted) > 0 {
+		msg += ", deleted " + strings.Join(e.Deleted, ", ")
+	}
+	if len(e.Left) > 0 {
+		msg += ", could not delete " + strings.Join(e.Left, ", ")
+	}
+	return msg
+}
+
+// MoveIPOptions contains the optional checks done
+// before moving an IPAddress between two VMs
+//
+// An unset field means no check is done
+type MoveIPOptions struct {
+	// State the VM the IP is moved from must be in,
+	// e.g. running or halted
+	FromState string
+
+	// State the VM the IP is moved to must be in
+	ToState string
 }
 
 // VMFilter is used to list virtual machines,
 // filtered with the options provided
+//
+// A VM must match every field set, a single value and a list
+// can both be given, e.g. `ID` and `IDs`. `Hostname` can be
+// a pattern, see MatchName
 type VMFilter struct {
 	RegionID string
 	Farm     string
 	Hostname string
 	ID       string
 	State    string
+
+	// The VM must be one of these
+	IDs       []string
+	RegionIDs []string
+	States    []string
+
+	// Range of creation dates
+	Created DateRange
+
+	// Tags the VMs must have, see VMTags
+	Tags Tags
+
+	// Order and fields of the VMs returned
+	Options ListOptions
 }
@@ -0,0 +1,283 @@
+package hosting
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// DefaultWatchInterval is the time between two lists
+// of a watch when WatchOptions.Interval is not positive
+const DefaultWatchInterval = 30 * time.Second
+
+// Types of the events sent by a watch
+const (
+	// The resource appeared, every resource of the first
+	// list of a watch is added
+	EventAdded = "added"
+
+	// The resource changed since the previous list
+	EventUpdated = "updated"
+
+	// The resource disappeared, the event contains
+	// its last known state
+	EventDeleted = "deleted"
+
+	// The resource did not change, sent for every
+	// resource on resync, see WatchOptions
+	EventSync = "sync"
+
+	// Listing the resources failed, the watch goes on
+	EventError = "error"
+)
+
+// WatchOptions configures a watch
+type WatchOptions struct {
+	// Time between two lists, DefaultWatchInterval if 0 or negative
+	Interval time.Duration
+
+	// Time between two resyncs, when an EventSync is sent for
+	// every resource that did not change so that a consumer
+	// that missed events can catch up, 0 disables resyncs
+	Resync time.Duration
+}
+
+// VMEvent is a change of a VM seen by WatchVMs
+type VMEvent struct {
+	Type string
+	VM   VM
+
+	// State of the VM before an EventUpdated
+	Old VM
+
+	// Error of an EventError
+	Err error
+}
+
+// StateChanged reports whether the event is an update
+// that changed the State of the VM, e.g. running to halted
+func (e VMEvent) StateChanged() bool {
+	return e.Type == EventUpdated && e.Old.State != e.VM.State
+}
+
+// DiskEvent is a change of a Disk seen by WatchDisks
+type DiskEvent struct {
+	Type string
+	Disk Disk
+
+	// State of the Disk before an EventUpdated
+	Old Disk
+
+	// Error of an EventError
+	Err error
+}
+
+// StateChanged reports whether the event is an update that
+// changed the State of the Disk, e.g. created to being_migrated
+func (e DiskEvent) StateChanged() bool {
+	return e.Type == EventUpdated && e.Old.State != e.Disk.State
+}
+
+// IPEvent is a change of an IP seen by WatchIPs
+type IPEvent struct {
+	Type string
+	IP   IPAddress
+
+	// State of the IP before an EventUpdated
+	Old IPAddress
+
+	// Error of an EventError
+	Err error
+}
+
+// StateChanged reports whether the event is an update
+// that changed the State of the IP, e.g. used to free
+func (e IPEvent) StateChanged() bool {
+	return e.Type == EventUpdated && e.Old.State != e.IP.State
+}
+
+// WatchVMs lists the VMs matching `filter` periodically and sends
+// an event for every change between two lists, until `ctx` is done
+//
+// The channel is closed once the watch stops. See ListOptions to
+// make the lists cheaper when only some fields are watched
+func WatchVMs(ctx context.Context, m VMManager, filter VMFilter, opts WatchOptions) <-chan VMEvent {
+	ch := make(chan VMEvent)
+	list := func() (map[string]interface{}, error) {
+		vms, err := m.ListVMs(filter)
+		if err != nil {
+			return nil, err
+		}
+		objects := make(map[string]interface{}, len(vms))
+		for _, vm := range vms {
+			objects[vm.ID] = vm
+		}
+		return objects, nil
+	}
+	send := func(c change) bool {
+		event := VMEvent{Type: c.Type, Err: c.Err}
+		event.VM, _ = c.Object.(VM)
+		event.Old, _ = c.Old.(VM)
+		select {
+		case ch <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	go func() {
+		defer close(ch)
+		watch(ctx, opts, list, send)
+	}()
+	return ch
+}
+
+// WatchDisks is WatchVMs for Disks
+func WatchDisks(ctx context.Context, m DiskManager, filter DiskFilter, opts WatchOptions) <-chan DiskEvent {
+	ch := make(chan DiskEvent)
+	list := func() (map[string]interface{}, error) {
+		disks, err := m.ListDisks(filter)
+		if err != nil {
+			return nil, err
+		}
+		objects := make(map[string]interface{}, len(disks))
+		for _, disk := range disks {
+			objects[disk.ID] = disk
+		}
+		return objects, nil
+	}
+	send := func(c change) bool {
+		event := DiskEvent{Type: c.Type, Err: c.Err}
+		event.Disk, _ = c.Object.(Disk)
+		event.Old, _ = c.Old.(Disk)
+		select {
+		case ch <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	go func() {
+		defer close(ch)
+		watch(ctx, opts, list, send)
+	}()
+	return ch
+}
+
+// WatchIPs is WatchVMs for IPs
+func WatchIPs(ctx context.Context, m IPManager, filter IPFilter, opts WatchOptions) <-chan IPEvent {
+	ch := make(chan IPEvent)
+	list := func() (map[string]interface{}, error) {
+		ips, err := m.ListIPs(filter)
+		if err != nil {
+			return nil, err
+		}
+		objects := make(map[string]interface{}, len(ips))
+		for _, ip := range ips {
+			objects[ip.ID] = ip
+		}
+		return objects, nil
+	}
+	send := func(c change) bool {
+		event := IPEvent{Type: c.Type, Err: c.Err}
+		event.IP, _ = c.Object.(IPAddress)
+		event.Old, _ = c.Old.(IPAddress)
+		select {
+		case ch <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	go func() {
+		defer close(ch)
+		watch(ctx, opts, list, send)
+	}()
+	return ch
+}
+
+// change is an event of any type of resource
+type change struct {
+	Type   string
+	Object interface{}
+	Old    interface{}
+	Err    error
+}
+
+// watch calls `list` every interval and diffs its result, indexed
+// by ID, with the previous one, every change is given to `send`
+//
+// It returns when `ctx` is done or when `send` returns false
+func watch(ctx context.Context, opts WatchOptions, list func() (map[string]interface{}, error), send func(change) bool) {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	known := map[string]interface{}{}
+	lastSync := time.Now()
+	for {
+		if err := ctx.Err(); err != nil {
+			return
+		}
+		current, err := list()
+		if err != nil {
+			if !send(change{Type: EventError, Err: err}) {
+				return
+			}
+		} else {
+			resync := opts.Resync > 0 && time.Since(lastSync) >= opts.Resync
+			if resync {
+				lastSync = time.Now()
+			}
+			for _, c := range diff(known, current, resync) {
+				if !send(c) {
+					return
+				}
+			}
+			known = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// diff returns the changes from `known` to `current`, ordered by ID,
+// with an EventSync for the unchanged resources if `resync` is set
+func diff(known, current map[string]interface{}, resync bool) []change {
+	var changes []change
+	for _, id := range sortedKeys(current) {
+		object := current[id]
+		old, ok := known[id]
+		switch {
+		case !ok:
+			changes = append(changes, change{Type: EventAdded, Object: object})
+		case !reflect.DeepEqual(old, object):
+			changes = append(changes, change{Type: EventUpdated, Object: object, Old: old})
+		case resync:
+			changes = append(changes, change{Type: EventSync, Object: object})
+		}
+	}
+	for _, id := range sortedKeys(known) {
+		if _, ok := current[id]; !ok {
+			changes = append(changes, change{Type: EventDeleted, Object: known[id]})
+		}
+	}
+	return changes
+}
+
+func sortedKeys(objects map[string]interface{}) []string {
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,135 @@
+package hosting
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// watchHosting returns a different list on each call,
+// the last one is repeated
+type watchHosting struct {
+	Hosting
+	mu    sync.Mutex
+	calls int
+	vms   [][]VM
+	disks [][]Disk
+	ips   [][]IPAddress
+}
+
+func (h *watchHosting) ListVMs(vmfilter VMFilter) ([]VM, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	i := h.calls
+	h.calls++
+	if i >= len(h.vms) {
+		i = len(h.vms) - 1
+	}
+	if h.vms[i] == nil {
+		return nil, errors.New("unavailable")
+	}
+	return h.vms[i], nil
+}
+
+func (h *watchHosting) ListDisks(diskfilter DiskFilter) ([]Disk, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	i := h.calls
+	h.calls++
+	if i >= len(h.disks) {
+		i = len(h.disks) - 1
+	}
+	return h.disks[i], nil
+}
+
+func (h *watchHosting) ListIPs(ipfilter IPFilter) ([]IPAddress, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	i := h.calls
+	h.calls++
+	if i >= len(h.ips) {
+		i = len(h.ips) - 1
+	}
+	return h.ips[i], nil
+}
+
+func TestWatchVMs(t *testing.T) {
+	h := &watchHosting{vms: [][]VM{
+		{{ID: "1", State: "running"}, {ID: "2", State: "running"}},
+		nil,
+		{{ID: "1", State: "halted"}, {ID: "3", State: "running"}},
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := WatchVMs(ctx, h, VMFilter{}, WatchOptions{Interval: time.Millisecond})
+	var got []string
+	for event := range events {
+		got = append(got, event.Type+" "+event.VM.ID)
+		if event.StateChanged() && event.Old.State != "running" {
+			t.Errorf("Error, unexpected previous state in %+v", event)
+		}
+		if len(got) == 6 {
+			cancel()
+		}
+	}
+
+	expected := []string{"added 1", "added 2", "error ", "updated 1", "added 3", "deleted 2"}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Error, expected %v, got instead %v", expected, got)
+	}
+}
+
+func TestWatchDisksResync(t *testing.T) {
+	h := &watchHosting{disks: [][]Disk{{{ID: "1", State: "created"}}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Every list after the first one resyncs
+	events := WatchDisks(ctx, h, DiskFilter{}, WatchOptions{Interval: time.Millisecond, Resync: time.Nanosecond})
+	first, second := <-events, <-events
+	if first.Type != EventAdded || second.Type != EventSync || second.Disk.ID != "1" {
+		t.Errorf("Error, unexpected events %+v, %+v", first, second)
+	}
+	cancel()
+	for range events {
+	}
+}
+
+func TestWatchIPs(t *testing.T) {
+	h := &watchHosting{ips: [][]IPAddress{
+		{{ID: "1", State: "used", VM: "10"}},
+		{{ID: "1", State: "free", VM: "0"}},
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := WatchIPs(ctx, h, IPFilter{}, WatchOptions{Interval: time.Millisecond})
+	added, updated := <-events, <-events
+	if added.Type != EventAdded || added.StateChanged() {
+		t.Errorf("Error, unexpected first event %+v", added)
+	}
+	if !updated.StateChanged() || updated.Old.State != "used" || updated.IP.State != "free" {
+		t.Errorf("Error, expected the IP to go from used to free, got instead %+v", updated)
+	}
+	cancel()
+	for range events {
+	}
+}
+
+func TestWatchNegativeInterval(t *testing.T) {
+	h := &watchHosting{ips: [][]IPAddress{{{ID: "1", State: "used"}}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := WatchIPs(ctx, h, IPFilter{}, WatchOptions{Interval: -time.Second})
+	if event := <-events; event.Type != EventAdded {
+		t.Errorf("Error, unexpected event %+v", event)
+	}
+	cancel()
+	for range events {
+	}
+}